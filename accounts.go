@@ -0,0 +1,331 @@
+package lvlup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ErrAccountNotFound is returned when there is no account with requested name.
+var ErrAccountNotFound = errors.New("account not found")
+
+// AccountsConfig represents configuration of all accounts, keyed by account name.
+type AccountsConfig struct {
+	Accounts map[string]Config `json:"accounts"`
+}
+
+// UnmarshalJSON decodes accounts config.
+// Errors of account configs point at fields like accounts.<name>.baseUrl.
+func (ac *AccountsConfig) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var raw struct {
+		Accounts map[string]json.RawMessage `json:"accounts"`
+	}
+
+	if err := decoder.Decode(&raw); err != nil {
+		return jsonConfigError(err)
+	}
+
+	accounts := make(map[string]Config, len(raw.Accounts))
+
+	for name, data := range raw.Accounts {
+		var config Config
+		if err := json.Unmarshal(data, &config); err != nil {
+			return accountConfigError(name, err)
+		}
+
+		accounts[name] = config
+	}
+
+	ac.Accounts = accounts
+
+	return nil
+}
+
+// accountConfigError prefixes field of *ConfigError with path of the account.
+func accountConfigError(name string, err error) error {
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return &ConfigError{Field: "accounts." + name + "." + configErr.Field, Err: configErr.Err}
+	}
+
+	return err
+}
+
+// AccountError represents a failed call made for a single account.
+type AccountError struct {
+	Account string
+	Err     error
+}
+
+// Error implements error interface.
+func (ae AccountError) Error() string {
+	return fmt.Sprintf("account %s: %v", ae.Account, ae.Err)
+}
+
+// Unwrap returns the underlying error.
+func (ae AccountError) Unwrap() error {
+	return ae.Err
+}
+
+// Accounts represents a registry of clients of named accounts.
+type Accounts struct {
+	mu      sync.RWMutex
+	clients map[string]*LvlClient
+}
+
+// NewAccounts creates new empty registry.
+func NewAccounts() *Accounts {
+	return &Accounts{
+		clients: map[string]*LvlClient{},
+	}
+}
+
+// LoadAccounts allows to create registry from json config file.
+// All clients share the httpClient.
+// It returns the registry and any errors encountered.
+func LoadAccounts(path string, httpClient *http.Client) (*Accounts, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var config AccountsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	return NewAccountsFromConfig(config, httpClient)
+}
+
+// NewAccountsFromConfig allows to create registry from config.
+// Accounts are created in order of their names.
+// It returns the registry and error of the first misconfigured account.
+func NewAccountsFromConfig(config AccountsConfig, httpClient *http.Client) (*Accounts, error) {
+	accounts := NewAccounts()
+
+	names := make([]string, 0, len(config.Accounts))
+	for name := range config.Accounts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		account := config.Accounts[name]
+		client, err := account.NewClient(httpClient)
+
+		if err != nil {
+			return nil, AccountError{Account: name, Err: accountConfigError(name, err)}
+		}
+
+		if err := accounts.Add(name, client); err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, nil
+}
+
+// Add allows to register client under the name.
+// It returns an error if the name is already used.
+func (a *Accounts) Add(name string, client *LvlClient) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.clients[name]; ok {
+		return fmt.Errorf("account %s already registered", name)
+	}
+
+	a.clients[name] = client
+
+	return nil
+}
+
+// Get allows to get client of the account.
+// It returns ErrAccountNotFound if there is no such account.
+func (a *Accounts) Get(name string) (*LvlClient, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	client, ok := a.clients[name]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, name)
+	}
+
+	return client, nil
+}
+
+// Names returns sorted names of registered accounts.
+func (a *Accounts) Names() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.clients))
+	for name := range a.clients {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// each calls fn concurrently for every account.
+// Errors returned by fn are collected in order of account names.
+func (a *Accounts) each(fn func(name string, client *LvlClient) error) []AccountError {
+	names := a.Names()
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		client, err := a.Get(name)
+
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(i int, name string, client *LvlClient) {
+			defer wg.Done()
+			errs[i] = fn(name, client)
+		}(i, name, client)
+	}
+
+	wg.Wait()
+
+	var accountErrors []AccountError
+
+	for i, err := range errs {
+		if err != nil {
+			accountErrors = append(accountErrors, AccountError{Account: names[i], Err: err})
+		}
+	}
+
+	return accountErrors
+}
+
+// AccountBalance represents wallet balance of a single account.
+type AccountBalance struct {
+	Account string
+	WalletBalanceResult
+}
+
+// AccountsBalance represents result of Accounts.WalletBalance func.
+// BalancePlnInt is the sum of balances of all accounts which were checked successfully.
+type AccountsBalance struct {
+	BalancePlnInt int
+	Accounts      []AccountBalance
+	Errors        []AccountError
+}
+
+// Partial reports whether balance of any account couldn't be checked.
+func (ab *AccountsBalance) Partial() bool {
+	return len(ab.Errors) > 0
+}
+
+// WalletBalance allows to get wallet balance of all accounts and their sum.
+// Failed accounts are reported in Errors of the result.
+func (a *Accounts) WalletBalance() *AccountsBalance {
+	var mu sync.Mutex
+	balances := map[string]WalletBalanceResult{}
+
+	errs := a.each(func(name string, client *LvlClient) error {
+		balance, err := client.WalletBalance()
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		balances[name] = *balance
+		mu.Unlock()
+
+		return nil
+	})
+
+	result := &AccountsBalance{
+		Errors: errs,
+	}
+
+	for _, name := range a.Names() {
+		balance, ok := balances[name]
+
+		if !ok {
+			continue
+		}
+
+		result.BalancePlnInt += balance.BalancePlnInt
+		result.Accounts = append(result.Accounts, AccountBalance{
+			Account:             name,
+			WalletBalanceResult: balance,
+		})
+	}
+
+	return result
+}
+
+// AccountService represents a service labeled with account it belongs to.
+type AccountService struct {
+	Account string `json:"account"`
+	Service
+}
+
+// AccountsServices represents result of Accounts.ListServices func.
+type AccountsServices struct {
+	Services []AccountService
+	Errors   []AccountError
+}
+
+// Partial reports whether services of any account couldn't be listed.
+func (as *AccountsServices) Partial() bool {
+	return len(as.Errors) > 0
+}
+
+// ListServices allows to list services of all accounts.
+// Services are ordered by account name. Failed accounts are reported in Errors of the result.
+func (a *Accounts) ListServices(opts ...ListServicesOption) *AccountsServices {
+	var mu sync.Mutex
+	services := map[string][]Service{}
+
+	errs := a.each(func(name string, client *LvlClient) error {
+		result, err := client.ListServices(opts...)
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		services[name] = result.Services
+		mu.Unlock()
+
+		return nil
+	})
+
+	result := &AccountsServices{
+		Errors: errs,
+	}
+
+	for _, name := range a.Names() {
+		for _, service := range services[name] {
+			result.Services = append(result.Services, AccountService{
+				Account: name,
+				Service: service,
+			})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,134 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// accountsHandler serves wallet and services of accounts identified by api key.
+// Requests with unknown keys fail with 500.
+func accountsHandler() testutil.RoundTripFunc {
+	balances := map[string]int{"Bearer prod": 1000, "Bearer reseller": 250}
+	services := map[string][]lvlup.Service{
+		"Bearer prod":     {{Id: 1, PlanName: "VPS"}},
+		"Bearer reseller": {{Id: 2, PlanName: "VPS"}, {Id: 3, PlanName: "Domain"}},
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		key := r.Header.Get("Authorization")
+
+		if _, ok := balances[key]; !ok {
+			return testutil.HttpError(http.StatusInternalServerError)(r)
+		}
+
+		return testutil.Route(map[string]testutil.RoundTripFunc{
+			"/v4/wallet":   testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: balances[key]}),
+			"/v4/services": testutil.JSON(http.StatusOK, lvlup.ListServicesResult{Services: services[key]}),
+		})(r)
+	}
+}
+
+func writeAccountsConfig(t *testing.T, config string) string {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	assert.Nil(t, os.WriteFile(path, []byte(config), 0600))
+
+	return path
+}
+
+func Test_load_accounts(t *testing.T) {
+	os.Setenv("LVLUP_RESELLER_KEY", "reseller")
+	defer os.Unsetenv("LVLUP_RESELLER_KEY")
+
+	path := writeAccountsConfig(t, `{
+		"accounts": {
+			"production": {"apiKey": "prod"},
+			"reseller": {"apiKeyEnv": "LVLUP_RESELLER_KEY"},
+			"sandbox": {"apiKey": "sandbox", "sandbox": true}
+		}
+	}`)
+
+	accounts, err := lvlup.LoadAccounts(path, &http.Client{Transport: accountsHandler()})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"production", "reseller", "sandbox"}, accounts.Names())
+
+	sandbox, err := accounts.Get("sandbox")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "https://api.sandbox.lvlup.pro/v4", sandbox.ApiBase)
+
+	_, err = accounts.Get("missing")
+
+	assert.True(t, errors.Is(err, lvlup.ErrAccountNotFound), "Error should be ErrAccountNotFound")
+}
+
+func Test_load_accounts_with_invalid_account(t *testing.T) {
+	path := writeAccountsConfig(t, `{"accounts": {"local": {"apiKey": "key", "baseUrl": "http://localhost"}}}`)
+
+	_, err := lvlup.LoadAccounts(path, http.DefaultClient)
+
+	var accountErr lvlup.AccountError
+	assert.True(t, errors.As(err, &accountErr), "Error should be AccountError")
+	assert.Equal(t, "local", accountErr.Account)
+	assert.True(t, errors.Is(err, lvlup.ErrInvalidBaseURL), "Error should be ErrInvalidBaseURL")
+}
+
+func Test_load_accounts_reports_first_invalid_account(t *testing.T) {
+	path := writeAccountsConfig(t, `{"accounts": {
+		"d": {"apiKey": "key", "baseUrl": "http://localhost"},
+		"b": {"apiKey": "key", "baseUrl": "http://localhost"},
+		"c": {"apiKey": "key", "baseUrl": "http://localhost"},
+		"a": {"apiKey": "key"}
+	}}`)
+
+	for i := 0; i < 10; i++ {
+		_, err := lvlup.LoadAccounts(path, http.DefaultClient)
+
+		var accountErr lvlup.AccountError
+		assert.True(t, errors.As(err, &accountErr), "Error should be AccountError")
+		assert.Equal(t, "b", accountErr.Account, "Accounts should be checked in order of names")
+	}
+}
+
+func Test_accounts_wallet_balance(t *testing.T) {
+	httpClient := &http.Client{Transport: accountsHandler()}
+
+	accounts := lvlup.NewAccounts()
+	assert.Nil(t, accounts.Add("production", lvlup.NewLvlClient("prod", httpClient)))
+	assert.Nil(t, accounts.Add("reseller", lvlup.NewLvlClient("reseller", httpClient)))
+	assert.Nil(t, accounts.Add("broken", lvlup.NewLvlClient("broken", httpClient)))
+	assert.NotNil(t, accounts.Add("production", lvlup.NewLvlClient("prod", httpClient)), "Duplicated account should be rejected")
+
+	balance := accounts.WalletBalance()
+
+	assert.Equal(t, 1250, balance.BalancePlnInt)
+	assert.Len(t, balance.Accounts, 2)
+	assert.Equal(t, "production", balance.Accounts[0].Account)
+	assert.True(t, balance.Partial())
+	assert.Equal(t, "broken", balance.Errors[0].Account)
+}
+
+func Test_accounts_list_services(t *testing.T) {
+	httpClient := &http.Client{Transport: accountsHandler()}
+
+	accounts := lvlup.NewAccounts()
+	assert.Nil(t, accounts.Add("production", lvlup.NewLvlClient("prod", httpClient)))
+	assert.Nil(t, accounts.Add("reseller", lvlup.NewLvlClient("reseller", httpClient)))
+
+	result := accounts.ListServices(lvlup.WithKind(lvlup.KindVPS))
+
+	assert.False(t, result.Partial())
+	assert.Len(t, result.Services, 2)
+	assert.Equal(t, "production", result.Services[0].Account)
+	assert.Equal(t, lvlup.ServiceID(1), result.Services[0].Id)
+	assert.Equal(t, "reseller", result.Services[1].Account)
+	assert.Equal(t, lvlup.ServiceID(2), result.Services[1].Id)
+}
@@ -0,0 +1,331 @@
+package lvlup
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrSessionNotFound is returned by CheckoutStore when there is no matching session.
+var ErrSessionNotFound = errors.New("checkout session not found")
+
+// CheckoutStoreError is returned together with session of a created payment which could not be stored.
+// The payment already exists, so the returned session is the only way to verify it.
+type CheckoutStoreError struct {
+	PaymentId PaymentID
+	Err       error
+}
+
+func (e *CheckoutStoreError) Error() string {
+	return fmt.Sprintf("checkout session of payment %s created but not stored: %v", e.PaymentId, e.Err)
+}
+
+func (e *CheckoutStoreError) Unwrap() error {
+	return e.Err
+}
+
+// CheckoutSession represents a payment created by Checkout together with its metadata.
+type CheckoutSession struct {
+	PaymentId PaymentID         `json:"paymentId"`
+	Url       string            `json:"url"`
+	Token     string            `json:"token"`
+	Amount    string            `json:"amount"`
+	OrderId   string            `json:"orderId"`
+	Customer  string            `json:"customer"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+}
+
+// CheckoutStore describes storage of checkout sessions.
+// Sessions are keyed by payment id and can be looked up by redirect token.
+type CheckoutStore interface {
+	Save(session *CheckoutSession) error
+	Get(paymentId PaymentID) (*CheckoutSession, error)
+	GetByToken(token string) (*CheckoutSession, error)
+}
+
+// MemoryCheckoutStore represents CheckoutStore keeping sessions in memory.
+type MemoryCheckoutStore struct {
+	mu       sync.RWMutex
+	sessions map[PaymentID]CheckoutSession
+	tokens   map[string]PaymentID
+}
+
+// NewMemoryCheckoutStore creates new in-memory store.
+func NewMemoryCheckoutStore() *MemoryCheckoutStore {
+	return &MemoryCheckoutStore{
+		sessions: map[PaymentID]CheckoutSession{},
+		tokens:   map[string]PaymentID{},
+	}
+}
+
+// Save stores the session.
+func (mcs *MemoryCheckoutStore) Save(session *CheckoutSession) error {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	mcs.sessions[session.PaymentId] = *session
+	mcs.tokens[session.Token] = session.PaymentId
+
+	return nil
+}
+
+// Get returns session of the payment.
+func (mcs *MemoryCheckoutStore) Get(paymentId PaymentID) (*CheckoutSession, error) {
+	mcs.mu.RLock()
+	defer mcs.mu.RUnlock()
+
+	session, ok := mcs.sessions[paymentId]
+
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	return &session, nil
+}
+
+// GetByToken returns session with the redirect token.
+func (mcs *MemoryCheckoutStore) GetByToken(token string) (*CheckoutSession, error) {
+	mcs.mu.RLock()
+	paymentId, ok := mcs.tokens[token]
+	mcs.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+
+	return mcs.Get(paymentId)
+}
+
+// CheckoutOptions represents available options for Checkout.
+type CheckoutOptions struct {
+	WebhookUrl   string
+	PollInterval time.Duration
+	TokenParam   string
+}
+
+// CheckoutOption represents a functional option for Checkout.
+type CheckoutOption func(*CheckoutOptions)
+
+// WithCheckoutWebhook sets webhook url passed to created payments.
+func WithCheckoutWebhook(url string) CheckoutOption {
+	return func(co *CheckoutOptions) {
+		co.WebhookUrl = url
+	}
+}
+
+// WithPollInterval sets how often Wait checks the payment.
+// Non-positive intervals are replaced with the default one.
+func WithPollInterval(interval time.Duration) CheckoutOption {
+	return func(co *CheckoutOptions) {
+		co.PollInterval = interval
+	}
+}
+
+// WithTokenParam sets name of the redirect url query parameter carrying the session token.
+func WithTokenParam(param string) CheckoutOption {
+	return func(co *CheckoutOptions) {
+		co.TokenParam = param
+	}
+}
+
+// Checkout creates payments for orders and verifies them when users return from LvlUp.
+type Checkout struct {
+	client      *LvlClient
+	store       CheckoutStore
+	redirectUrl string
+	options     *CheckoutOptions
+}
+
+// defaultPollInterval is how often Wait checks the payment if no valid interval is set.
+const defaultPollInterval = 5 * time.Second
+
+// NewCheckout creates new checkout redirecting users to redirectUrl after payment.
+// A session token is appended to redirectUrl, so RedirectHandler should be served there.
+func NewCheckout(client *LvlClient, store CheckoutStore, redirectUrl string, opts ...CheckoutOption) *Checkout {
+	options := &CheckoutOptions{
+		PollInterval: defaultPollInterval,
+		TokenParam:   "checkout",
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultPollInterval
+	}
+
+	return &Checkout{
+		client:      client,
+		store:       store,
+		redirectUrl: redirectUrl,
+		options:     options,
+	}
+}
+
+// Begin allows to create a payment for an order and store its session.
+// Users should be redirected to the returned session Url.
+// If the session can't be stored, it's returned with *CheckoutStoreError, so the
+// created payment isn't lost.
+// It returns the session and any errors encountered.
+func (c *Checkout) Begin(amount string, orderId string, customer string, metadata map[string]string) (*CheckoutSession, error) {
+	token, err := newCheckoutToken()
+
+	if err != nil {
+		return nil, err
+	}
+
+	redirect, err := url.Parse(c.redirectUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := redirect.Query()
+	query.Set(c.options.TokenParam, token)
+	redirect.RawQuery = query.Encode()
+
+	opts := []CreatePaymentOption{WithRedirect(redirect.String())}
+
+	if c.options.WebhookUrl != "" {
+		opts = append(opts, WithWebhook(c.options.WebhookUrl))
+	}
+
+	payment, err := c.client.CreatePayment(amount, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	session := &CheckoutSession{
+		PaymentId: payment.Id,
+		Url:       payment.Url,
+		Token:     token,
+		Amount:    amount,
+		OrderId:   orderId,
+		Customer:  customer,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+
+	if err := c.store.Save(session); err != nil {
+		return session, &CheckoutStoreError{PaymentId: session.PaymentId, Err: err}
+	}
+
+	return session, nil
+}
+
+// Status allows to check current state of the payment with the checkout client.
+// It's the check Wait and RedirectHandler rely on, so handlers holding only
+// the Checkout can inspect payments the same way.
+// It returns *APIError matching ErrNotFound if the api does not know the payment.
+func (c *Checkout) Status(paymentId PaymentID) (*InspectPaymentResult, error) {
+	payment, err := c.client.InspectPayment(paymentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// Wait allows to block until the payment is payed or ctx is done.
+// It returns the payed payment or the first error encountered.
+func (c *Checkout) Wait(ctx context.Context, paymentId PaymentID) (*InspectPaymentResult, error) {
+	ticker := time.NewTicker(c.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		payment, err := c.Status(paymentId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if payment.Payed {
+			return payment, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkoutContextKey is a key under which RedirectHandler stores verified session.
+type checkoutContextKey struct{}
+
+// CheckoutFromContext allows to access session and payment verified by RedirectHandler.
+func CheckoutFromContext(ctx context.Context) (*CheckoutSession, *InspectPaymentResult, bool) {
+	verified, ok := ctx.Value(checkoutContextKey{}).(checkoutVerification)
+
+	if !ok {
+		return nil, nil, false
+	}
+
+	return verified.session, verified.payment, true
+}
+
+// checkoutVerification represents result of verifying a returning user.
+type checkoutVerification struct {
+	session *CheckoutSession
+	payment *InspectPaymentResult
+}
+
+// RedirectHandler returns handler for users returning from LvlUp.
+// It looks up the session by token and inspects the payment server-side.
+// Payed payments are passed to success, others to pending, with the session
+// available through CheckoutFromContext. Unknown tokens result in 404.
+func (c *Checkout) RedirectHandler(success http.Handler, pending http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get(c.options.TokenParam)
+
+		session, err := c.store.GetByToken(token)
+
+		if errors.Is(err, ErrSessionNotFound) {
+			http.NotFound(w, r)
+			return
+		} else if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		payment, err := c.Status(session.PaymentId)
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), checkoutContextKey{}, checkoutVerification{
+			session: session,
+			payment: payment,
+		})
+
+		if payment.Payed {
+			success.ServeHTTP(w, r.WithContext(ctx))
+		} else {
+			pending.ServeHTTP(w, r.WithContext(ctx))
+		}
+	})
+}
+
+// newCheckoutToken generates random session token.
+func newCheckoutToken() (string, error) {
+	token := make([]byte, 16)
+
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
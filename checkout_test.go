@@ -0,0 +1,201 @@
+package lvlup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkoutServer simulates api creating payments which can be marked as payed.
+type checkoutServer struct {
+	mu        sync.Mutex
+	redirects map[string]string
+	payed     map[string]bool
+}
+
+func newCheckoutServer() *checkoutServer {
+	return &checkoutServer{
+		redirects: map[string]string{},
+		payed:     map[string]bool{},
+	}
+}
+
+func (cs *checkoutServer) pay(paymentId string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.payed[paymentId] = true
+}
+
+func (cs *checkoutServer) handler() testutil.RoundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+
+		if r.Method == http.MethodPost && r.URL.Path == "/v4/wallet/up" {
+			var body lvlup.CreatePaymentOptions
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				return nil, err
+			}
+
+			cs.redirects["p1"] = body.RedirectUrl
+			return testutil.JSON(http.StatusOK, lvlup.CreatePaymentResult{Id: "p1", Url: "https://pay.example/p1"})(r)
+		}
+
+		if r.URL.Path == "/v4/wallet/up/p1" {
+			return testutil.JSON(http.StatusOK, lvlup.InspectPaymentResult{Payed: cs.payed["p1"], AmountInt: 1000})(r)
+		}
+
+		return testutil.HttpError(http.StatusNotFound)(r)
+	}
+}
+
+func Test_checkout_begin(t *testing.T) {
+	server := newCheckoutServer()
+	store := lvlup.NewMemoryCheckoutStore()
+	checkout := lvlup.NewCheckout(testutil.NewTestLvlClient("token", server.handler()), store, "https://shop.example/return?lang=pl")
+
+	session, err := checkout.Begin("10.00", "order-1", "jan@example.pl", map[string]string{"sku": "vps"})
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentID("p1"), session.PaymentId)
+	assert.Equal(t, "https://pay.example/p1", session.Url)
+
+	redirect, err := url.Parse(server.redirects["p1"])
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "pl", redirect.Query().Get("lang"))
+	assert.Equal(t, session.Token, redirect.Query().Get("checkout"))
+
+	stored, err := store.Get("p1")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "order-1", stored.OrderId)
+	assert.Equal(t, "vps", stored.Metadata["sku"])
+}
+
+// failingCheckoutStore represents CheckoutStore which can't save sessions.
+type failingCheckoutStore struct {
+	lvlup.CheckoutStore
+}
+
+func (failingCheckoutStore) Save(*lvlup.CheckoutSession) error {
+	return errors.New("disk full")
+}
+
+func Test_checkout_begin_session_not_stored(t *testing.T) {
+	server := newCheckoutServer()
+	checkout := lvlup.NewCheckout(
+		testutil.NewTestLvlClient("token", server.handler()),
+		failingCheckoutStore{lvlup.NewMemoryCheckoutStore()},
+		"https://shop.example/return",
+	)
+
+	session, err := checkout.Begin("10.00", "order-1", "jan@example.pl", nil)
+
+	var storeErr *lvlup.CheckoutStoreError
+	assert.True(t, errors.As(err, &storeErr), "Error should be CheckoutStoreError")
+	assert.Equal(t, lvlup.PaymentID("p1"), storeErr.PaymentId)
+	assert.Equal(t, "disk full", errors.Unwrap(err).Error())
+
+	if assert.NotNil(t, session, "Session of the created payment should be returned") {
+		assert.Equal(t, lvlup.PaymentID("p1"), session.PaymentId)
+		assert.Equal(t, "order-1", session.OrderId)
+	}
+}
+
+func Test_checkout_status_and_wait(t *testing.T) {
+	server := newCheckoutServer()
+	checkout := lvlup.NewCheckout(
+		testutil.NewTestLvlClient("token", server.handler()),
+		lvlup.NewMemoryCheckoutStore(),
+		"https://shop.example/return",
+		lvlup.WithPollInterval(time.Millisecond),
+	)
+
+	status, err := checkout.Status("p1")
+	assert.Nil(t, err, "Error should be nil")
+	assert.False(t, status.Payed)
+
+	_, err = checkout.Status("unknown")
+	assert.True(t, errors.Is(err, lvlup.ErrNotFound), "Error should be ErrNotFound")
+
+	var apiErr *lvlup.APIError
+	assert.True(t, errors.As(err, &apiErr), "Error should be APIError")
+	assert.Equal(t, "InspectPayment", apiErr.Operation)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		server.pay("p1")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	payment, err := checkout.Wait(ctx, "p1")
+	assert.Nil(t, err, "Error should be nil")
+	assert.True(t, payment.Payed)
+}
+
+func Test_checkout_redirect_handler(t *testing.T) {
+	server := newCheckoutServer()
+	checkout := lvlup.NewCheckout(
+		testutil.NewTestLvlClient("token", server.handler()),
+		lvlup.NewMemoryCheckoutStore(),
+		"https://shop.example/return",
+	)
+
+	session, err := checkout.Begin("10.00", "order-1", "", nil)
+	assert.Nil(t, err, "Error should be nil")
+
+	respond := func(body string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified, _, ok := lvlup.CheckoutFromContext(r.Context())
+			assert.True(t, ok)
+			assert.Equal(t, "order-1", verified.OrderId)
+
+			w.Write([]byte(body))
+		})
+	}
+
+	handler := checkout.RedirectHandler(respond("success"), respond("pending"))
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/return?checkout="+token, nil))
+		return recorder
+	}
+
+	assert.Equal(t, "pending", serve(session.Token).Body.String())
+
+	server.pay("p1")
+	assert.Equal(t, "success", serve(session.Token).Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve("forged").Code)
+}
+
+func Test_checkout_invalid_poll_interval(t *testing.T) {
+	server := newCheckoutServer()
+	checkout := lvlup.NewCheckout(
+		testutil.NewTestLvlClient("token", server.handler()),
+		lvlup.NewMemoryCheckoutStore(),
+		"https://shop.example/return",
+		lvlup.WithPollInterval(0),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NotPanics(t, func() {
+		_, err := checkout.Wait(ctx, "p1")
+		assert.Equal(t, context.Canceled, err)
+	})
+}
@@ -1,15 +1,43 @@
 package lvlup
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
+const (
+	apiHost           = "https://api.lvlup.pro"
+	sandboxApiHost    = "https://api.sandbox.lvlup.pro"
+	defaultApiVersion = "v4"
+)
+
+// ErrInvalidBaseURL is returned by requests of a client configured with invalid base url.
+var ErrInvalidBaseURL = errors.New("invalid base url")
+
 // LvlClient describes properties stored by the client.
 type LvlClient struct {
-	ApiKey      string
-	ApiBase     string
-	SandboxMode bool
-	HttpClient  *http.Client
+	ApiKey           Secret
+	Credentials      CredentialsProvider
+	ApiBase          string
+	SandboxMode      bool
+	HttpClient       *http.Client
+	IdempotencyStore IdempotencyStore
+
+	baseURL       string
+	apiVersion    string
+	allowInsecure bool
+	configErr     error
+	retryPolicy   RetryPolicy
+	limiter       *rateLimiter
+	quotas        *quotaTracker
+	driftHandler  func(SchemaDrift)
+	strictDecode  bool
+	responseMeta  *ResponseMeta
+	ctx           context.Context
 }
 
 // LvlClientOption describes functional option for the client.
@@ -19,22 +47,138 @@ type LvlClientOption func(*LvlClient)
 func WithSandboxMode() LvlClientOption {
 	return func(lc *LvlClient) {
 		lc.SandboxMode = true
-		lc.ApiBase = "https://api.sandbox.lvlup.pro/v4"
+	}
+}
+
+// WithBaseURL sets url of the api, for example of a local fake or a proxy.
+// The api version is appended to it, so it should not contain one.
+// It takes precedence over the host selected by WithSandboxMode.
+func WithBaseURL(baseURL string) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.baseURL = baseURL
+	}
+}
+
+// WithAPIVersion sets version of the api used by the client. Defaults to v4.
+func WithAPIVersion(version string) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.apiVersion = version
+	}
+}
+
+// WithInsecureBaseURL allows base url with http scheme. It's meant for tests only.
+func WithInsecureBaseURL() LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.allowInsecure = true
+	}
+}
+
+// WithIdempotencyStore sets store remembering payments created with WithIdempotencyKey.
+func WithIdempotencyStore(store IdempotencyStore) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.IdempotencyStore = store
 	}
 }
 
 // NewLvlClient creates new lvlup api client.
 func NewLvlClient(apiKey string, httpClient *http.Client, opts ...LvlClientOption) *LvlClient {
 	lc := &LvlClient{
-		ApiKey:      apiKey,
-		ApiBase:     "https://api.lvlup.pro/v4",
+		ApiKey:      Secret(apiKey),
 		SandboxMode: false,
 		HttpClient:  httpClient,
+		apiVersion:  defaultApiVersion,
+		quotas:      newQuotaTracker(),
 	}
 
 	for _, opt := range opts {
 		opt(lc)
 	}
 
+	lc.ApiBase, lc.configErr = lc.resolveApiBase()
+
 	return lc
 }
+
+// WithContext allows to get a copy of the client which sends requests with ctx.
+// When ctx is done, requests in flight are aborted and waits for rate limits,
+// quotas and retries are interrupted, failing with ctx error.
+func (lc LvlClient) WithContext(ctx context.Context) *LvlClient {
+	lc.ctx = ctx
+	return &lc
+}
+
+// context returns context of the client requests, context.Background if none is set.
+func (lc LvlClient) context() context.Context {
+	if lc.ctx == nil {
+		return context.Background()
+	}
+
+	return lc.ctx
+}
+
+// Err returns error in client configuration, for example invalid base url.
+// Requests made by misconfigured client fail with the same error.
+func (lc LvlClient) Err() error {
+	return lc.configErr
+}
+
+// resolveApiBase builds ApiBase from base url, sandbox mode and api version.
+func (lc *LvlClient) resolveApiBase() (string, error) {
+	base := apiHost
+	if lc.SandboxMode {
+		base = sandboxApiHost
+	}
+
+	if lc.baseURL != "" {
+		base = lc.baseURL
+	}
+
+	version, err := checkAPIVersion(lc.apiVersion)
+
+	if err != nil {
+		return "", err
+	}
+
+	base, err = checkBaseURL(base, lc.allowInsecure)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base + "/" + version, nil
+}
+
+// checkAPIVersion returns the version without surrounding slashes.
+// It returns an error if the version can't be used as a path segment.
+func checkAPIVersion(version string) (string, error) {
+	trimmed := strings.Trim(version, "/")
+
+	if trimmed == "" || strings.Contains(trimmed, "/") {
+		return "", fmt.Errorf("%w: api version %q", ErrInvalidBaseURL, version)
+	}
+
+	return trimmed, nil
+}
+
+// checkBaseURL returns the url without trailing slash.
+// It returns an error if the url is not an absolute https url.
+func checkBaseURL(base string, allowInsecure bool) (string, error) {
+	parsed, err := url.Parse(base)
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidBaseURL, err)
+	}
+
+	if parsed.Host == "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBaseURL, base)
+	}
+
+	switch {
+	case parsed.Scheme == "https":
+	case parsed.Scheme == "http" && allowInsecure:
+	default:
+		return "", fmt.Errorf("%w: scheme of %q is not https", ErrInvalidBaseURL, base)
+	}
+
+	return strings.TrimSuffix(parsed.String(), "/"), nil
+}
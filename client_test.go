@@ -1,10 +1,12 @@
 package lvlup_test
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,3 +17,69 @@ func Test_create_client_with_sandbox_mode_option(t *testing.T) {
 	assert.Equal(t, client.ApiBase, "https://api.sandbox.lvlup.pro/v4")
 	assert.True(t, client.SandboxMode)
 }
+
+func Test_create_client_with_default_base(t *testing.T) {
+	client := lvlup.NewLvlClient("key", http.DefaultClient)
+
+	assert.Nil(t, client.Err(), "Error should be nil")
+	assert.Equal(t, "https://api.lvlup.pro/v4", client.ApiBase)
+}
+
+func Test_create_client_with_base_url_and_version(t *testing.T) {
+	client := lvlup.NewLvlClient("key", http.DefaultClient,
+		lvlup.WithBaseURL("https://proxy.example.com/lvlup/"),
+		lvlup.WithAPIVersion("v5"),
+		lvlup.WithSandboxMode(),
+	)
+
+	assert.Nil(t, client.Err(), "Error should be nil")
+	assert.Equal(t, "https://proxy.example.com/lvlup/v5", client.ApiBase)
+	assert.True(t, client.SandboxMode)
+}
+
+func Test_create_client_with_sandbox_mode_and_version(t *testing.T) {
+	client := lvlup.NewLvlClient("key", http.DefaultClient, lvlup.WithAPIVersion("v5"), lvlup.WithSandboxMode())
+
+	assert.Equal(t, "https://api.sandbox.lvlup.pro/v5", client.ApiBase)
+}
+
+func Test_create_client_with_invalid_base_url(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []lvlup.LvlClientOption
+	}{
+		{"http", []lvlup.LvlClientOption{lvlup.WithBaseURL("http://localhost:8080")}},
+		{"no host", []lvlup.LvlClientOption{lvlup.WithBaseURL("/v4")}},
+		{"query", []lvlup.LvlClientOption{lvlup.WithBaseURL("https://example.com?x=1")}},
+		{"malformed", []lvlup.LvlClientOption{lvlup.WithBaseURL("https://exa mple.com")}},
+		{"empty version", []lvlup.LvlClientOption{lvlup.WithAPIVersion("")}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := testutil.NewTestLvlClient("key", func(r *http.Request) (*http.Response, error) {
+				t.Errorf("Request made by misconfigured client to %v", r.URL)
+				return testutil.HttpError(http.StatusOK)(r)
+			}, test.opts...)
+
+			assert.True(t, errors.Is(client.Err(), lvlup.ErrInvalidBaseURL), "Error should be ErrInvalidBaseURL")
+
+			_, err := client.WalletBalance()
+			assert.True(t, errors.Is(err, lvlup.ErrInvalidBaseURL), "Error should be ErrInvalidBaseURL")
+		})
+	}
+}
+
+func Test_create_client_with_insecure_base_url(t *testing.T) {
+	var requested string
+
+	client := testutil.NewTestLvlClient("key", func(r *http.Request) (*http.Response, error) {
+		requested = r.URL.String()
+		return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{})(r)
+	}, lvlup.WithBaseURL("http://localhost:8080"), lvlup.WithInsecureBaseURL())
+
+	_, err := client.WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "http://localhost:8080/v4/wallet", requested)
+}
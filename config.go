@@ -0,0 +1,481 @@
+package lvlup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigError represents invalid value of a config field.
+// Field is the json path of the field, or the environment variable it was read from.
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+// Error implements error interface.
+func (ce *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config field %s: %v", ce.Field, ce.Err)
+}
+
+// Unwrap returns the underlying error.
+func (ce *ConfigError) Unwrap() error {
+	return ce.Err
+}
+
+// RateLimitConfig represents limit of requests made by a client.
+type RateLimitConfig struct {
+	PerSecond float64 `json:"perSecond"`
+	Burst     int     `json:"burst"`
+}
+
+// Config represents configuration of a client.
+// The api key is taken from ApiKey, ApiKeyEnv environment variable or ApiKeyFile.
+// Durations are written in json as strings like "30s".
+type Config struct {
+	ApiKey        Secret          `json:"apiKey"`
+	ApiKeyEnv     string          `json:"apiKeyEnv"`
+	ApiKeyFile    string          `json:"apiKeyFile"`
+	Sandbox       bool            `json:"sandbox"`
+	BaseURL       string          `json:"baseUrl"`
+	APIVersion    string          `json:"apiVersion"`
+	AllowInsecure bool            `json:"allowInsecure"`
+	Timeout       time.Duration   `json:"timeout"`
+	Retry         RetryPolicy     `json:"retry"`
+	RateLimit     RateLimitConfig `json:"rateLimit"`
+}
+
+// rawConfig represents Config as written in json.
+type rawConfig struct {
+	ApiKey        Secret `json:"apiKey"`
+	ApiKeyEnv     string `json:"apiKeyEnv"`
+	ApiKeyFile    string `json:"apiKeyFile"`
+	Sandbox       bool   `json:"sandbox"`
+	BaseURL       string `json:"baseUrl"`
+	APIVersion    string `json:"apiVersion"`
+	AllowInsecure bool   `json:"allowInsecure"`
+	Timeout       string `json:"timeout"`
+	Retry         struct {
+		MaxRetries int    `json:"maxRetries"`
+		Backoff    string `json:"backoff"`
+		MaxBackoff string `json:"maxBackoff"`
+
+		RetryIdempotentPosts bool `json:"retryIdempotentPosts"`
+	} `json:"retry"`
+	RateLimit RateLimitConfig `json:"rateLimit"`
+}
+
+// UnmarshalJSON decodes config rejecting unknown fields.
+// Errors are reported as *ConfigError pointing at the invalid field.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var raw rawConfig
+	if err := decoder.Decode(&raw); err != nil {
+		return jsonConfigError(err)
+	}
+
+	config := Config{
+		ApiKey:        raw.ApiKey,
+		ApiKeyEnv:     raw.ApiKeyEnv,
+		ApiKeyFile:    raw.ApiKeyFile,
+		Sandbox:       raw.Sandbox,
+		BaseURL:       raw.BaseURL,
+		APIVersion:    raw.APIVersion,
+		AllowInsecure: raw.AllowInsecure,
+		Retry: RetryPolicy{
+			MaxRetries:           raw.Retry.MaxRetries,
+			RetryIdempotentPosts: raw.Retry.RetryIdempotentPosts,
+		},
+		RateLimit: raw.RateLimit,
+	}
+
+	durations := []struct {
+		field string
+		value string
+		dst   *time.Duration
+	}{
+		{"timeout", raw.Timeout, &config.Timeout},
+		{"retry.backoff", raw.Retry.Backoff, &config.Retry.Backoff},
+		{"retry.maxBackoff", raw.Retry.MaxBackoff, &config.Retry.MaxBackoff},
+	}
+
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+
+		value, err := time.ParseDuration(duration.value)
+
+		if err != nil {
+			return &ConfigError{Field: duration.field, Err: err}
+		}
+
+		*duration.dst = value
+	}
+
+	*c = config
+
+	return nil
+}
+
+// MarshalJSON encodes config with durations written as strings and redacted api key.
+func (c Config) MarshalJSON() ([]byte, error) {
+	var raw rawConfig
+
+	raw.ApiKey = c.ApiKey
+	raw.ApiKeyEnv = c.ApiKeyEnv
+	raw.ApiKeyFile = c.ApiKeyFile
+	raw.Sandbox = c.Sandbox
+	raw.BaseURL = c.BaseURL
+	raw.APIVersion = c.APIVersion
+	raw.AllowInsecure = c.AllowInsecure
+	raw.Retry.MaxRetries = c.Retry.MaxRetries
+	raw.Retry.RetryIdempotentPosts = c.Retry.RetryIdempotentPosts
+	raw.RateLimit = c.RateLimit
+
+	for _, duration := range []struct {
+		dst   *string
+		value time.Duration
+	}{
+		{&raw.Timeout, c.Timeout},
+		{&raw.Retry.Backoff, c.Retry.Backoff},
+		{&raw.Retry.MaxBackoff, c.Retry.MaxBackoff},
+	} {
+		if duration.value != 0 {
+			*duration.dst = duration.value.String()
+		}
+	}
+
+	return json.Marshal(raw)
+}
+
+// jsonConfigError converts json decoding errors into *ConfigError where possible.
+func jsonConfigError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &ConfigError{Field: typeErr.Field, Err: fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+
+	if message := err.Error(); strings.HasPrefix(message, "json: unknown field ") {
+		field, _ := strconv.Unquote(strings.TrimPrefix(message, "json: unknown field "))
+		return &ConfigError{Field: field, Err: errors.New("unknown field")}
+	}
+
+	return err
+}
+
+// Validate checks whether the config describes a working client.
+// It returns *ConfigError pointing at the first invalid field.
+func (c *Config) Validate() error {
+	sources := 0
+	for _, source := range []string{c.ApiKey.Reveal(), c.ApiKeyEnv, c.ApiKeyFile} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	switch {
+	case sources == 0:
+		return &ConfigError{Field: "apiKey", Err: errors.New("one of apiKey, apiKeyEnv and apiKeyFile is required")}
+	case sources > 1:
+		return &ConfigError{Field: "apiKey", Err: errors.New("only one of apiKey, apiKeyEnv and apiKeyFile can be set")}
+	}
+
+	if c.BaseURL != "" {
+		if _, err := checkBaseURL(c.BaseURL, c.AllowInsecure); err != nil {
+			return &ConfigError{Field: "baseUrl", Err: err}
+		}
+	}
+
+	if c.APIVersion != "" {
+		if _, err := checkAPIVersion(c.APIVersion); err != nil {
+			return &ConfigError{Field: "apiVersion", Err: err}
+		}
+	}
+
+	negative := []struct {
+		field string
+		value float64
+	}{
+		{"timeout", float64(c.Timeout)},
+		{"retry.maxRetries", float64(c.Retry.MaxRetries)},
+		{"retry.backoff", float64(c.Retry.Backoff)},
+		{"retry.maxBackoff", float64(c.Retry.MaxBackoff)},
+		{"rateLimit.perSecond", c.RateLimit.PerSecond},
+		{"rateLimit.burst", float64(c.RateLimit.Burst)},
+	}
+
+	for _, field := range negative {
+		if field.value < 0 {
+			return &ConfigError{Field: field.field, Err: errors.New("must not be negative")}
+		}
+	}
+
+	if c.RateLimit.Burst > 0 && c.RateLimit.PerSecond == 0 {
+		return &ConfigError{Field: "rateLimit.perSecond", Err: errors.New("is required when burst is set")}
+	}
+
+	return nil
+}
+
+// Options returns client options described by the config.
+// Timeout is not included, it's applied to http client by NewClient.
+func (c *Config) Options() []LvlClientOption {
+	var opts []LvlClientOption
+
+	switch {
+	case c.ApiKeyEnv != "":
+		opts = append(opts, WithCredentials(EnvCredentials(c.ApiKeyEnv)))
+	case c.ApiKeyFile != "":
+		opts = append(opts, WithCredentials(NewFileCredentials(c.ApiKeyFile)))
+	}
+
+	if c.Sandbox {
+		opts = append(opts, WithSandboxMode())
+	}
+
+	if c.BaseURL != "" {
+		opts = append(opts, WithBaseURL(c.BaseURL))
+	}
+
+	if c.APIVersion != "" {
+		opts = append(opts, WithAPIVersion(c.APIVersion))
+	}
+
+	if c.AllowInsecure {
+		opts = append(opts, WithInsecureBaseURL())
+	}
+
+	if c.Retry.MaxRetries > 0 {
+		opts = append(opts, WithRetryPolicy(c.Retry))
+	}
+
+	if c.RateLimit.PerSecond > 0 {
+		opts = append(opts, WithRateLimit(c.RateLimit.PerSecond, c.RateLimit.Burst))
+	}
+
+	return opts
+}
+
+// NewClient allows to create client described by the config.
+// If httpClient is nil a new one is created. Timeout is applied to a copy of httpClient.
+// It returns the client and any errors encountered.
+func (c *Config) NewClient(httpClient *http.Client) (*LvlClient, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	if c.Timeout > 0 {
+		withTimeout := *httpClient
+		withTimeout.Timeout = c.Timeout
+		httpClient = &withTimeout
+	}
+
+	client := NewLvlClient(c.ApiKey.Reveal(), httpClient, c.Options()...)
+
+	return client, client.Err()
+}
+
+// envField represents config field read from environment variable.
+type envField struct {
+	env   string
+	field string
+	set   func(c *Config, value string) error
+}
+
+// envFields lists environment variables read by ConfigFromEnv.
+var envFields = []envField{
+	{"LVLUP_API_KEY", "apiKey", func(c *Config, value string) error {
+		c.ApiKey = Secret(value)
+		return nil
+	}},
+	{"LVLUP_API_KEY_FILE", "apiKeyFile", func(c *Config, value string) error {
+		c.ApiKeyFile = value
+		return nil
+	}},
+	{"LVLUP_SANDBOX", "sandbox", func(c *Config, value string) (err error) {
+		c.Sandbox, err = strconv.ParseBool(value)
+		return err
+	}},
+	{"LVLUP_BASE_URL", "baseUrl", func(c *Config, value string) error {
+		c.BaseURL = value
+		return nil
+	}},
+	{"LVLUP_API_VERSION", "apiVersion", func(c *Config, value string) error {
+		c.APIVersion = value
+		return nil
+	}},
+	{"LVLUP_ALLOW_INSECURE", "allowInsecure", func(c *Config, value string) (err error) {
+		c.AllowInsecure, err = strconv.ParseBool(value)
+		return err
+	}},
+	{"LVLUP_TIMEOUT", "timeout", func(c *Config, value string) (err error) {
+		c.Timeout, err = time.ParseDuration(value)
+		return err
+	}},
+	{"LVLUP_RETRY_MAX_RETRIES", "retry.maxRetries", func(c *Config, value string) (err error) {
+		c.Retry.MaxRetries, err = strconv.Atoi(value)
+		return err
+	}},
+	{"LVLUP_RETRY_BACKOFF", "retry.backoff", func(c *Config, value string) (err error) {
+		c.Retry.Backoff, err = time.ParseDuration(value)
+		return err
+	}},
+	{"LVLUP_RETRY_MAX_BACKOFF", "retry.maxBackoff", func(c *Config, value string) (err error) {
+		c.Retry.MaxBackoff, err = time.ParseDuration(value)
+		return err
+	}},
+	{"LVLUP_RETRY_IDEMPOTENT_POSTS", "retry.retryIdempotentPosts", func(c *Config, value string) (err error) {
+		c.Retry.RetryIdempotentPosts, err = strconv.ParseBool(value)
+		return err
+	}},
+	{"LVLUP_RATE_LIMIT_PER_SECOND", "rateLimit.perSecond", func(c *Config, value string) (err error) {
+		c.RateLimit.PerSecond, err = strconv.ParseFloat(value, 64)
+		return err
+	}},
+	{"LVLUP_RATE_LIMIT_BURST", "rateLimit.burst", func(c *Config, value string) (err error) {
+		c.RateLimit.Burst, err = strconv.Atoi(value)
+		return err
+	}},
+}
+
+// ConfigFromEnv allows to read config from LVLUP_* environment variables,
+// for example LVLUP_API_KEY, LVLUP_SANDBOX or LVLUP_RETRY_MAX_RETRIES.
+// Errors are reported as *ConfigError with Field set to the variable name.
+func ConfigFromEnv() (*Config, error) {
+	return configFromLookup(os.LookupEnv)
+}
+
+// configFromLookup reads and validates config from variables returned by lookup.
+func configFromLookup(lookup func(string) (string, bool)) (*Config, error) {
+	config := &Config{}
+
+	for _, field := range envFields {
+		value, ok := lookup(field.env)
+
+		if !ok || value == "" {
+			continue
+		}
+
+		if err := field.set(config, value); err != nil {
+			return nil, &ConfigError{Field: field.env, Err: err}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			for _, field := range envFields {
+				if field.field == configErr.Field {
+					return nil, &ConfigError{Field: field.env, Err: configErr.Err}
+				}
+			}
+		}
+
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadConfig allows to read config from a file.
+// Files with .env extension contain KEY=VALUE lines with the same variables as
+// read by ConfigFromEnv, other files are read as json.
+// It returns validated config and any errors encountered.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Ext(path) == ".env" || filepath.Base(path) == ".env" {
+		values, err := parseEnvFile(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return configFromLookup(func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		})
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// parseEnvFile parses KEY=VALUE lines. Empty lines and lines starting with # are skipped.
+func parseEnvFile(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, ok := cutString(strings.TrimPrefix(text, "export "), "=")
+
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", line)
+		}
+
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+
+		values[strings.TrimSpace(key)] = value
+	}
+
+	return values, scanner.Err()
+}
+
+// cutString slices s around the first instance of sep.
+func cutString(s string, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+// NewClientFromEnv allows to create client configured with LVLUP_* environment variables.
+// It returns the client and any errors encountered.
+func NewClientFromEnv() (*LvlClient, error) {
+	config, err := ConfigFromEnv()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return config.NewClient(nil)
+}
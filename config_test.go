@@ -0,0 +1,128 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+
+	return path
+}
+
+func Test_load_json_config(t *testing.T) {
+	path := writeConfig(t, "lvlup.json", `{
+		"apiKey": "key",
+		"sandbox": true,
+		"timeout": "10s",
+		"retry": {"maxRetries": 3, "backoff": "200ms", "maxBackoff": "2s"},
+		"rateLimit": {"perSecond": 5, "burst": 10}
+	}`)
+
+	config, err := lvlup.LoadConfig(path)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, &lvlup.Config{
+		ApiKey:  "key",
+		Sandbox: true,
+		Timeout: 10 * time.Second,
+		Retry: lvlup.RetryPolicy{
+			MaxRetries: 3,
+			Backoff:    200 * time.Millisecond,
+			MaxBackoff: 2 * time.Second,
+		},
+		RateLimit: lvlup.RateLimitConfig{PerSecond: 5, Burst: 10},
+	}, config)
+
+	client, err := config.NewClient(nil)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "https://api.sandbox.lvlup.pro/v4", client.ApiBase)
+	assert.Equal(t, 10*time.Second, client.HttpClient.Timeout)
+}
+
+func Test_load_env_file_config(t *testing.T) {
+	path := writeConfig(t, "lvlup.env", `
+# production account
+LVLUP_API_KEY="key"
+export LVLUP_BASE_URL=https://proxy.example.com/lvlup
+LVLUP_RETRY_MAX_RETRIES=2
+`)
+
+	config, err := lvlup.LoadConfig(path)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "key", config.ApiKey.Reveal())
+	assert.Equal(t, "https://proxy.example.com/lvlup", config.BaseURL)
+	assert.Equal(t, 2, config.Retry.MaxRetries)
+}
+
+func Test_load_config_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		field   string
+	}{
+		{"missing key", "lvlup.json", `{}`, "apiKey"},
+		{"two keys", "lvlup.json", `{"apiKey": "key", "apiKeyFile": "/key"}`, "apiKey"},
+		{"unknown field", "lvlup.json", `{"apiKey": "key", "sandboxMode": true}`, "sandboxMode"},
+		{"wrong type", "lvlup.json", `{"apiKey": "key", "retry": {"maxRetries": "3"}}`, "retry.maxRetries"},
+		{"invalid duration", "lvlup.json", `{"apiKey": "key", "retry": {"backoff": "soon"}}`, "retry.backoff"},
+		{"http base url", "lvlup.json", `{"apiKey": "key", "baseUrl": "http://localhost"}`, "baseUrl"},
+		{"negative timeout", "lvlup.json", `{"apiKey": "key", "timeout": "-1s"}`, "timeout"},
+		{"burst without rate", "lvlup.json", `{"apiKey": "key", "rateLimit": {"burst": 2}}`, "rateLimit.perSecond"},
+		{"env invalid bool", "lvlup.env", "LVLUP_API_KEY=key\nLVLUP_SANDBOX=maybe", "LVLUP_SANDBOX"},
+		{"env missing key", "lvlup.env", "LVLUP_SANDBOX=true", "LVLUP_API_KEY"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := lvlup.LoadConfig(writeConfig(t, test.file, test.content))
+
+			var configErr *lvlup.ConfigError
+			assert.True(t, errors.As(err, &configErr), "Error should be ConfigError, got %v", err)
+			assert.Equal(t, test.field, configErr.Field)
+		})
+	}
+}
+
+func Test_new_client_from_env(t *testing.T) {
+	for key, value := range map[string]string{
+		"LVLUP_API_KEY":               "key",
+		"LVLUP_API_VERSION":           "v5",
+		"LVLUP_TIMEOUT":               "5s",
+		"LVLUP_RATE_LIMIT_PER_SECOND": "2.5",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	client, err := lvlup.NewClientFromEnv()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "key", client.ApiKey.Reveal())
+	assert.Equal(t, "https://api.lvlup.pro/v5", client.ApiBase)
+	assert.Equal(t, 5*time.Second, client.HttpClient.Timeout)
+	assert.NotEqual(t, http.DefaultClient, client.HttpClient)
+}
+
+func Test_accounts_config_error_field(t *testing.T) {
+	path := writeConfig(t, "accounts.json", `{"accounts": {"reseller": {"apiKey": "key", "timeout": "often"}}}`)
+
+	_, err := lvlup.LoadAccounts(path, http.DefaultClient)
+
+	var configErr *lvlup.ConfigError
+	assert.True(t, errors.As(err, &configErr), "Error should be ConfigError")
+	assert.Equal(t, "accounts.reseller.timeout", configErr.Field)
+}
@@ -0,0 +1,189 @@
+package lvlup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned when a provider has no api key to offer.
+var ErrNoCredentials = errors.New("no credentials")
+
+// CredentialsProvider describes source of the api key.
+// ApiKey is called for every request, so rotated keys are picked up without
+// recreating the client. Refresh is called once after the api responds with
+// 401 Unauthorized, before the request is retried with the new key.
+// Implementations have to be safe for concurrent use.
+type CredentialsProvider interface {
+	ApiKey() (string, error)
+	Refresh() error
+}
+
+// StaticCredentials represents provider returning the same api key.
+type StaticCredentials string
+
+// ApiKey returns the api key.
+func (sc StaticCredentials) ApiKey() (string, error) {
+	if sc == "" {
+		return "", ErrNoCredentials
+	}
+
+	return string(sc), nil
+}
+
+// String returns redacted api key.
+func (sc StaticCredentials) String() string {
+	return redacted
+}
+
+// Format writes redacted api key regardless of the verb.
+func (sc StaticCredentials) Format(f fmt.State, verb rune) {
+	Secret(sc).Format(f, verb)
+}
+
+// MarshalJSON encodes redacted api key.
+func (sc StaticCredentials) MarshalJSON() ([]byte, error) {
+	return Secret(sc).MarshalJSON()
+}
+
+// Refresh does nothing, static key can't change.
+func (sc StaticCredentials) Refresh() error {
+	return nil
+}
+
+// EnvCredentials represents provider reading api key from environment variable.
+type EnvCredentials string
+
+// ApiKey returns current value of the environment variable.
+func (ec EnvCredentials) ApiKey() (string, error) {
+	key := strings.TrimSpace(os.Getenv(string(ec)))
+
+	if key == "" {
+		return "", fmt.Errorf("%w: environment variable %s is empty", ErrNoCredentials, string(ec))
+	}
+
+	return key, nil
+}
+
+// Refresh does nothing, the variable is read for every request.
+func (ec EnvCredentials) Refresh() error {
+	return nil
+}
+
+// CredentialsFunc represents provider calling the func for every request.
+type CredentialsFunc func() (string, error)
+
+// ApiKey returns result of the func.
+func (cf CredentialsFunc) ApiKey() (string, error) {
+	return cf()
+}
+
+// Refresh does nothing, the func is called for every request.
+func (cf CredentialsFunc) Refresh() error {
+	return nil
+}
+
+// FileCredentials represents provider reading api key from a file.
+// The file is read again when its modification time or size changes.
+type FileCredentials struct {
+	path string
+
+	mu      sync.RWMutex
+	key     Secret
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials creates new provider reading api key from the file.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{
+		path: path,
+	}
+}
+
+// String returns description of the provider without the api key.
+func (fc *FileCredentials) String() string {
+	return fmt.Sprintf("FileCredentials(%s)", fc.path)
+}
+
+// Format writes description of the provider regardless of the verb.
+// The cached api key is kept in unexported field, which fmt would print as is.
+func (fc *FileCredentials) Format(f fmt.State, verb rune) {
+	io.WriteString(f, fc.String())
+}
+
+// ApiKey returns api key from the file, reading it again if the file changed.
+func (fc *FileCredentials) ApiKey() (string, error) {
+	info, err := os.Stat(fc.path)
+
+	if err != nil {
+		return "", err
+	}
+
+	fc.mu.RLock()
+	key, changed := fc.key.Reveal(), !info.ModTime().Equal(fc.modTime) || info.Size() != fc.size
+	fc.mu.RUnlock()
+
+	if !changed && key != "" {
+		return key, nil
+	}
+
+	if err := fc.Refresh(); err != nil {
+		return "", err
+	}
+
+	fc.mu.RLock()
+	defer fc.mu.RUnlock()
+
+	return fc.key.Reveal(), nil
+}
+
+// Refresh reads the file again.
+func (fc *FileCredentials) Refresh() error {
+	info, err := os.Stat(fc.path)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fc.path)
+
+	if err != nil {
+		return err
+	}
+
+	key := strings.TrimSpace(string(data))
+
+	if key == "" {
+		return fmt.Errorf("%w: file %s is empty", ErrNoCredentials, fc.path)
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.key = Secret(key)
+	fc.modTime = info.ModTime()
+	fc.size = info.Size()
+
+	return nil
+}
+
+// WithCredentials sets provider of the api key used instead of the ApiKey field.
+func WithCredentials(provider CredentialsProvider) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.Credentials = provider
+	}
+}
+
+// credentials returns provider of the api key used by the client.
+func (lc LvlClient) credentials() CredentialsProvider {
+	if lc.Credentials != nil {
+		return lc.Credentials
+	}
+
+	return StaticCredentials(lc.ApiKey.Reveal())
+}
@@ -0,0 +1,178 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// authorizedHandler responds with wallet balance if request uses the valid key.
+func authorizedHandler(valid func() string, requests *[]string) testutil.RoundTripFunc {
+	var mu sync.Mutex
+
+	return func(r *http.Request) (*http.Response, error) {
+		mu.Lock()
+		*requests = append(*requests, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+valid() {
+			return testutil.HttpError(http.StatusUnauthorized)(r)
+		}
+
+		return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: 100})(r)
+	}
+}
+
+func Test_static_credentials(t *testing.T) {
+	var requests []string
+	client := testutil.NewTestLvlClient("key", authorizedHandler(func() string { return "key" }, &requests))
+
+	_, err := client.WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"Bearer key"}, requests)
+}
+
+func Test_empty_static_credentials(t *testing.T) {
+	client := testutil.NewTestLvlClient("", testutil.HttpError(http.StatusOK))
+
+	_, err := client.WalletBalance()
+
+	assert.True(t, errors.Is(err, lvlup.ErrNoCredentials), "Error should be ErrNoCredentials")
+}
+
+func Test_env_credentials(t *testing.T) {
+	os.Setenv("LVLUP_TEST_KEY", "first")
+	defer os.Unsetenv("LVLUP_TEST_KEY")
+
+	var requests []string
+	client := testutil.NewTestLvlClient("", authorizedHandler(func() string { return os.Getenv("LVLUP_TEST_KEY") }, &requests),
+		lvlup.WithCredentials(lvlup.EnvCredentials("LVLUP_TEST_KEY")),
+	)
+
+	_, err := client.WalletBalance()
+	assert.Nil(t, err, "Error should be nil")
+
+	os.Setenv("LVLUP_TEST_KEY", "second")
+
+	_, err = client.WalletBalance()
+	assert.Nil(t, err, "Error should be nil")
+
+	assert.Equal(t, []string{"Bearer first", "Bearer second"}, requests)
+}
+
+func Test_file_credentials_rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	assert.Nil(t, os.WriteFile(path, []byte("first\n"), 0600))
+
+	credentials := lvlup.NewFileCredentials(path)
+
+	key, err := credentials.ApiKey()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "first", key)
+
+	assert.Nil(t, os.WriteFile(path, []byte("second-key\n"), 0600))
+	future := time.Now().Add(time.Second)
+	assert.Nil(t, os.Chtimes(path, future, future))
+
+	key, err = credentials.ApiKey()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "second-key", key)
+}
+
+func Test_refresh_and_retry_on_unauthorized(t *testing.T) {
+	var mu sync.Mutex
+	current := "old"
+
+	var requests []string
+	handler := authorizedHandler(func() string { return "new" }, &requests)
+
+	client := testutil.NewTestLvlClient("", handler, lvlup.WithCredentials(lvlup.CredentialsFunc(func() (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := current
+		current = "new"
+
+		return key, nil
+	})))
+
+	result, err := client.WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 100, result.BalancePlnInt)
+	assert.Equal(t, []string{"Bearer old", "Bearer new"}, requests)
+}
+
+func Test_unauthorized_is_retried_once(t *testing.T) {
+	var requests []string
+	client := testutil.NewTestLvlClient("", authorizedHandler(func() string { return "valid" }, &requests),
+		lvlup.WithCredentials(lvlup.StaticCredentials("invalid")),
+	)
+
+	_, err := client.WalletBalance()
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, []string{"Bearer invalid"}, requests)
+}
+
+// failingRefreshCredentials represents credentials which can't be refreshed.
+type failingRefreshCredentials struct {
+	lvlup.StaticCredentials
+}
+
+func (failingRefreshCredentials) Refresh() error {
+	return os.ErrNotExist
+}
+
+func Test_unauthorized_with_failed_refresh(t *testing.T) {
+	var requests []string
+	client := testutil.NewTestLvlClient("", authorizedHandler(func() string { return "valid" }, &requests),
+		lvlup.WithCredentials(failingRefreshCredentials{"invalid"}),
+	)
+
+	_, err := client.WalletBalance()
+
+	var apiErr *lvlup.APIError
+	assert.True(t, errors.As(err, &apiErr), "Error should be APIError")
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "Error should hold the refresh error")
+	assert.Equal(t, "WalletBalance: 401 Unauthorized (refreshing credentials: file does not exist)", err.Error())
+	assert.Equal(t, []string{"Bearer invalid"}, requests)
+}
+
+func Test_credentials_rotation_during_requests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	assert.Nil(t, os.WriteFile(path, []byte("key"), 0600))
+
+	var requests []string
+	client := testutil.NewTestLvlClient("", authorizedHandler(func() string { return "key" }, &requests),
+		lvlup.WithCredentials(lvlup.NewFileCredentials(path)),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := client.WalletBalance()
+			assert.Nil(t, err, "Error should be nil")
+		}()
+	}
+
+	rotated := path + ".new"
+	assert.Nil(t, os.WriteFile(rotated, []byte("key\n"), 0600))
+	assert.Nil(t, os.Rename(rotated, path))
+	wg.Wait()
+}
@@ -0,0 +1,269 @@
+package lvlup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaDrift is returned in strict decoding mode when a response doesn't match its type.
+var ErrSchemaDrift = errors.New("schema drift")
+
+// SchemaDrift represents differences between a response of an operation and the type it's decoded into.
+// Fields are reported as paths, for example items[].amount.
+type SchemaDrift struct {
+	Operation string
+	// Unknown lists fields present in the response, but not in the type.
+	Unknown []string
+	// Missing lists fields of the type absent from the response.
+	Missing []string
+}
+
+// Empty reports whether the response matched its type.
+func (sd SchemaDrift) Empty() bool {
+	return len(sd.Unknown) == 0 && len(sd.Missing) == 0
+}
+
+// SchemaDriftError is returned in strict decoding mode when a response doesn't match its type.
+type SchemaDriftError struct {
+	Drift SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	return fmt.Sprintf("%v in %s response: unknown fields %v, missing fields %v",
+		ErrSchemaDrift, e.Drift.Operation, e.Drift.Unknown, e.Drift.Missing)
+}
+
+func (e *SchemaDriftError) Unwrap() error {
+	return ErrSchemaDrift
+}
+
+// WithSchemaDriftHandler sets func called with differences between a response and its type.
+// Responses are still decoded as usual, so it's meant for logging warnings.
+func WithSchemaDriftHandler(handler func(SchemaDrift)) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.driftHandler = handler
+	}
+}
+
+// WithStrictDecoding makes requests fail with SchemaDriftError when a response doesn't match its type.
+// It's meant for tests against the sandbox or recorded responses.
+func WithStrictDecoding() LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.strictDecode = true
+	}
+}
+
+// decode decodes response body of the operation into v.
+// Unless drift detection is enabled, the body is decoded without any checks.
+func (lc LvlClient) decode(operation string, body io.Reader, v interface{}) error {
+	if lc.driftHandler == nil && !lc.strictDecode {
+		return json.NewDecoder(body).Decode(v)
+	}
+
+	data, err := io.ReadAll(body)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	drift := schemaDrift(operation, raw, reflect.TypeOf(v))
+
+	if drift.Empty() {
+		return nil
+	}
+
+	if lc.driftHandler != nil {
+		lc.driftHandler(drift)
+	}
+
+	if lc.strictDecode {
+		return &SchemaDriftError{Drift: drift}
+	}
+
+	return nil
+}
+
+// schemaDrift compares decoded json value with type t.
+func schemaDrift(operation string, value interface{}, t reflect.Type) SchemaDrift {
+	unknown, missing := map[string]bool{}, map[string]bool{}
+	compareSchema(value, t, "", unknown, missing)
+
+	return SchemaDrift{
+		Operation: operation,
+		Unknown:   sortedKeys(unknown),
+		Missing:   sortedKeys(missing),
+	}
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// compareSchema records fields of value unknown to t and fields of t missing from value.
+// Types with own UnmarshalJSON are not inspected.
+func compareSchema(value interface{}, t reflect.Type, path string, unknown, missing map[string]bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if value == nil || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+
+		if !ok {
+			return
+		}
+
+		for _, item := range items {
+			compareSchema(item, t.Elem(), path+"[]", unknown, missing)
+		}
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+
+		if !ok {
+			return
+		}
+
+		fields := jsonFields(t)
+
+		for key, item := range object {
+			field, ok := findJSONField(fields, key)
+
+			if !ok {
+				unknown[joinFieldPath(path, key)] = true
+				continue
+			}
+
+			compareSchema(item, field.typ, joinFieldPath(path, field.name), unknown, missing)
+		}
+
+		for _, field := range fields {
+			if field.optional {
+				continue
+			}
+
+			if !hasJSONKey(object, field.name) {
+				missing[joinFieldPath(path, field.name)] = true
+			}
+		}
+	}
+}
+
+// jsonField represents struct field as seen by encoding/json.
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// jsonFields returns fields of the struct encoded by encoding/json, including promoted ones.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := cutString(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, jsonField{
+			name:     name,
+			typ:      field.Type,
+			optional: strings.Contains(options, "omitempty"),
+		})
+	}
+
+	return fields
+}
+
+// findJSONField returns field matching the key the way encoding/json does,
+// preferring exact match over case-insensitive one.
+func findJSONField(fields []jsonField, key string) (jsonField, bool) {
+	for _, field := range fields {
+		if field.name == key {
+			return field, true
+		}
+	}
+
+	for _, field := range fields {
+		if strings.EqualFold(field.name, key) {
+			return field, true
+		}
+	}
+
+	return jsonField{}, false
+}
+
+// hasJSONKey reports whether the object has key matching the field name.
+func hasJSONKey(object map[string]interface{}, name string) bool {
+	if _, ok := object[name]; ok {
+		return true
+	}
+
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinFieldPath appends the field name to the path.
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// sortedKeys returns keys of the set in order.
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(set))
+
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
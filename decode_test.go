@@ -0,0 +1,118 @@
+package lvlup_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureHandler responds to every request with the golden response of the operation.
+func fixtureHandler(t *testing.T, operation string) testutil.RoundTripFunc {
+	data, err := os.ReadFile(filepath.Join("testdata", "responses", operation+".json"))
+	assert.Nil(t, err, "Error should be nil")
+
+	return testutil.JSON(http.StatusOK, json.RawMessage(data))
+}
+
+// goldenCalls calls operations with golden responses and returns their results.
+var goldenCalls = map[string]func(client *lvlup.LvlClient) (interface{}, error){
+	"CreatePayment": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.CreatePayment("10.00")
+	},
+	"ListPayments": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.ListPayments()
+	},
+	"WalletBalance": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.WalletBalance()
+	},
+	"InspectPayment": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.InspectPayment("abc123")
+	},
+	"ListServices": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.ListServices()
+	},
+	"GetVPSState": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.GetVPSState(7)
+	},
+	"ListDDoSAttacks": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.ListDDoSAttacks(7)
+	},
+	"GetProxmoUser": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.GetProxmoUser(7)
+	},
+	"GetUDPFilter": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.GetUDPFilter(7)
+	},
+	"SetUDPFiltering": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.SetUDPFiltering(7, true)
+	},
+	"ListUDPFilterExceptions": func(client *lvlup.LvlClient) (interface{}, error) {
+		return client.ListUDPFilterExceptions(7)
+	},
+}
+
+func Test_golden_responses_decode_strictly(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "responses", "*.json"))
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, files, len(goldenCalls), "Every golden response should have a call")
+
+	for operation, call := range goldenCalls {
+		t.Run(operation, func(t *testing.T) {
+			client := testutil.NewTestLvlClient("token", fixtureHandler(t, operation), lvlup.WithStrictDecoding())
+
+			_, err := call(client)
+
+			assert.Nil(t, err, "Error should be nil")
+		})
+	}
+}
+
+func Test_golden_responses_fill_fields(t *testing.T) {
+	payment, err := goldenCalls["InspectPayment"](testutil.NewTestLvlClient("token", fixtureHandler(t, "InspectPayment")))
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "10.00", payment.(*lvlup.InspectPaymentResult).AmountStr)
+
+	exceptions, err := goldenCalls["ListUDPFilterExceptions"](testutil.NewTestLvlClient("token", fixtureHandler(t, "ListUDPFilterExceptions")))
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 25565, exceptions.([]lvlup.UDPFilterException)[0].Ports[0].From)
+}
+
+func Test_schema_drift(t *testing.T) {
+	handler := testutil.JSON(http.StatusOK, json.RawMessage(`{
+		"count": 1,
+		"items": [{"amount": "1.00", "createdAt": "", "id": 1, "methodId": 1, "serviceId": 1, "currency": "PLN"}]
+	}`))
+
+	var drifts []lvlup.SchemaDrift
+	client := testutil.NewTestLvlClient("token", handler, lvlup.WithSchemaDriftHandler(func(drift lvlup.SchemaDrift) {
+		drifts = append(drifts, drift)
+	}))
+
+	result, err := client.ListPayments()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, []lvlup.SchemaDrift{{
+		Operation: "ListPayments",
+		Unknown:   []string{"items[].currency"},
+		Missing:   []string{"items[].description"},
+	}}, drifts)
+
+	strict := testutil.NewTestLvlClient("token", handler, lvlup.WithStrictDecoding())
+
+	_, err = strict.ListPayments()
+
+	var driftErr *lvlup.SchemaDriftError
+	assert.True(t, errors.As(err, &driftErr), "Error should be SchemaDriftError")
+	assert.True(t, errors.Is(err, lvlup.ErrSchemaDrift), "Error should be ErrSchemaDrift")
+	assert.True(t, strings.Contains(err.Error(), "ListPayments"), "Error should name the operation")
+}
@@ -0,0 +1,69 @@
+package lvlup
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNotFound is returned when the api responds with 404 Not Found,
+// for example when inspecting payment which doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// maxErrorMessageSize limits how much of a response body is kept in APIError.
+const maxErrorMessageSize = 4 << 10
+
+// APIError represents response of the api with unexpected status.
+// It matches ErrNotFound with errors.Is if the status is 404 Not Found.
+type APIError struct {
+	Operation  string
+	StatusCode int
+	Status     string
+	// Message holds response body, if any.
+	Message string
+	// CredentialsErr holds error of refreshing credentials after 401 Unauthorized, if it failed.
+	// It's returned by Unwrap.
+	CredentialsErr error
+}
+
+// newAPIError creates new error from the response, reading message from its body.
+func newAPIError(operation string, response *http.Response) *APIError {
+	message, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorMessageSize))
+
+	status := response.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return &APIError{
+		Operation:  operation,
+		StatusCode: response.StatusCode,
+		Status:     status,
+		Message:    strings.TrimSpace(string(message)),
+	}
+}
+
+func (e *APIError) Error() string {
+	message := fmt.Sprintf("%s: %s", e.Operation, e.Status)
+
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+
+	if e.CredentialsErr != nil {
+		message += fmt.Sprintf(" (refreshing credentials: %v)", e.CredentialsErr)
+	}
+
+	return message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.CredentialsErr
+}
+
+// Is reports whether the error matches target.
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
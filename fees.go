@@ -0,0 +1,168 @@
+package lvlup
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PaymentFee represents fee breakdown of a payment. Amounts are in grosz.
+// Gross is the amount payed by the customer, Net is the amount credited to the wallet.
+type PaymentFee struct {
+	GrossPlnInt   int     `json:"grossPlnInt"`
+	FeePlnInt     int     `json:"feePlnInt"`
+	NetPlnInt     int     `json:"netPlnInt"`
+	EffectiveRate float64 `json:"effectiveRate"`
+}
+
+// newPaymentFee calculates breakdown from gross and net amounts.
+func newPaymentFee(gross int, net int) PaymentFee {
+	fee := PaymentFee{
+		GrossPlnInt: gross,
+		FeePlnInt:   gross - net,
+		NetPlnInt:   net,
+	}
+
+	if gross != 0 {
+		fee.EffectiveRate = float64(fee.FeePlnInt) / float64(gross)
+	}
+
+	return fee
+}
+
+// Fee allows to get fee breakdown of the inspected payment.
+func (ipr InspectPaymentResult) Fee() PaymentFee {
+	return newPaymentFee(ipr.AmountWithFeeInt, ipr.AmountInt)
+}
+
+// FeeRule represents fee charged for a payment method.
+// The fee is Percent of the amount plus FixedPlnInt, but not less than MinPlnInt.
+type FeeRule struct {
+	Percent     float64 `json:"percent"`
+	FixedPlnInt int     `json:"fixedPlnInt"`
+	MinPlnInt   int     `json:"minPlnInt"`
+}
+
+// Apply calculates fee in grosz charged for gross amount in grosz.
+func (fr FeeRule) Apply(gross int) int {
+	fee := int(math.Round(float64(gross)*fr.Percent/100)) + fr.FixedPlnInt
+
+	if fee < fr.MinPlnInt {
+		fee = fr.MinPlnInt
+	}
+
+	return fee
+}
+
+// FeeTable represents fee rules of payment methods.
+// Default applies to methods missing from Methods.
+type FeeTable struct {
+	Methods map[PaymentMethod]FeeRule `json:"methods"`
+	Default FeeRule                   `json:"default"`
+}
+
+// Rule returns fee rule of the method.
+func (ft FeeTable) Rule(method PaymentMethod) FeeRule {
+	if rule, ok := ft.Methods[method]; ok {
+		return rule
+	}
+
+	return ft.Default
+}
+
+// FeeEstimate represents estimated fee of a payment made with a method.
+type FeeEstimate struct {
+	Method PaymentMethod `json:"methodId"`
+	PaymentFee
+}
+
+// EstimateFees allows to estimate fees of an amount like "24.99" for every method in the table.
+// Unless the table has a rule of MethodUnknown, an estimate of MethodUnknown calculated
+// with the Default rule is included, covering methods missing from the table.
+// It returns estimates sorted by method and any errors encountered while parsing the amount.
+func (ft FeeTable) EstimateFees(amount string) ([]FeeEstimate, error) {
+	gross, err := parseAmount(amount)
+
+	if err != nil {
+		return nil, err
+	}
+
+	estimates := make([]FeeEstimate, 0, len(ft.Methods)+1)
+
+	if _, ok := ft.Methods[MethodUnknown]; !ok {
+		estimates = append(estimates, FeeEstimate{
+			Method:     MethodUnknown,
+			PaymentFee: newPaymentFee(gross, gross-ft.Default.Apply(gross)),
+		})
+	}
+
+	for method, rule := range ft.Methods {
+		estimates = append(estimates, FeeEstimate{
+			Method:     method,
+			PaymentFee: newPaymentFee(gross, gross-rule.Apply(gross)),
+		})
+	}
+
+	sort.Slice(estimates, func(i, j int) bool { return estimates[i].Method < estimates[j].Method })
+
+	return estimates, nil
+}
+
+// MethodFees represents fees aggregated over payments made with a method.
+type MethodFees struct {
+	Method PaymentMethod `json:"methodId"`
+	Count  int           `json:"count"`
+	PaymentFee
+}
+
+// add includes fee of a single payment.
+func (mf *MethodFees) add(fee PaymentFee) {
+	mf.Count++
+	mf.PaymentFee = newPaymentFee(mf.GrossPlnInt+fee.GrossPlnInt, mf.NetPlnInt+fee.NetPlnInt)
+}
+
+// FeeReport represents result of AggregateFees func.
+type FeeReport struct {
+	Methods []MethodFees `json:"methods"`
+	Total   MethodFees   `json:"total"`
+}
+
+// AggregateFees allows to sum fees of payments matching the filter, grouped by method.
+// Fees are calculated from the table, as listed payments don't include them.
+// It returns the report and any errors encountered.
+func (lc LvlClient) AggregateFees(table FeeTable, filter PaymentsFilter) (*FeeReport, error) {
+	methods := map[PaymentMethod]*MethodFees{}
+	report := &FeeReport{}
+
+	err := filter.each(lc, 100, func(item ListPaymentsResultItem, createdAt time.Time) error {
+		gross, err := parseAmount(item.Amount)
+
+		if err != nil {
+			return err
+		}
+
+		method := item.Method()
+		fee := newPaymentFee(gross, gross-table.Rule(method).Apply(gross))
+
+		if _, ok := methods[method]; !ok {
+			methods[method] = &MethodFees{Method: method}
+		}
+
+		methods[method].add(fee)
+		report.Total.add(fee)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, method := range methods {
+		report.Methods = append(report.Methods, *method)
+	}
+
+	sort.Slice(report.Methods, func(i, j int) bool { return report.Methods[i].Method < report.Methods[j].Method })
+
+	return report, nil
+}
@@ -0,0 +1,98 @@
+package lvlup_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func feeTable() lvlup.FeeTable {
+	return lvlup.FeeTable{
+		Methods: map[lvlup.PaymentMethod]lvlup.FeeRule{
+			lvlup.PaymentMethod(1): {Percent: 2},
+			lvlup.PaymentMethod(2): {Percent: 3.5, FixedPlnInt: 30},
+		},
+		Default: lvlup.FeeRule{Percent: 10, MinPlnInt: 100},
+	}
+}
+
+func Test_inspect_payment_fee(t *testing.T) {
+	fee := lvlup.InspectPaymentResult{AmountInt: 950, AmountWithFeeInt: 1000}.Fee()
+
+	assert.Equal(t, 1000, fee.GrossPlnInt)
+	assert.Equal(t, 50, fee.FeePlnInt)
+	assert.Equal(t, 950, fee.NetPlnInt)
+	assert.InDelta(t, 0.05, fee.EffectiveRate, 0.0001)
+}
+
+func Test_fee_rule_apply(t *testing.T) {
+	assert.Equal(t, 20, lvlup.FeeRule{Percent: 2}.Apply(1000))
+	assert.Equal(t, 65, lvlup.FeeRule{Percent: 3.5, FixedPlnInt: 30}.Apply(1000))
+	assert.Equal(t, 100, lvlup.FeeRule{Percent: 1, MinPlnInt: 100}.Apply(1000))
+}
+
+func Test_estimate_fees(t *testing.T) {
+	estimates, err := feeTable().EstimateFees("10.00")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, estimates, 3)
+	assert.Equal(t, lvlup.MethodUnknown, estimates[0].Method)
+	assert.Equal(t, 100, estimates[0].FeePlnInt)
+	assert.Equal(t, lvlup.PaymentMethod(1), estimates[1].Method)
+	assert.Equal(t, 980, estimates[1].NetPlnInt)
+	assert.Equal(t, lvlup.PaymentMethod(2), estimates[2].Method)
+	assert.Equal(t, 65, estimates[2].FeePlnInt)
+
+	_, err = feeTable().EstimateFees("ten")
+	assert.NotNil(t, err, "Error should not be nil")
+}
+
+func Test_estimate_fees_default_only(t *testing.T) {
+	table := lvlup.FeeTable{Default: lvlup.FeeRule{Percent: 5}}
+
+	estimates, err := table.EstimateFees("20.00")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []lvlup.FeeEstimate{{
+		Method:     lvlup.MethodUnknown,
+		PaymentFee: lvlup.PaymentFee{GrossPlnInt: 2000, FeePlnInt: 100, NetPlnInt: 1900, EffectiveRate: 0.05},
+	}}, estimates)
+}
+
+func Test_aggregate_fees(t *testing.T) {
+	items := []lvlup.ListPaymentsResultItem{
+		{Id: 1, Amount: "10.00", CreatedAt: "2021-06-01T00:00:00Z", MethodId: 1},
+		{Id: 2, Amount: "20.00", CreatedAt: "2021-06-02T00:00:00Z", MethodId: 1},
+		{Id: 3, Amount: "10.00", CreatedAt: "2021-06-03T00:00:00Z", MethodId: 9},
+		{Id: 4, Amount: "10.00", CreatedAt: "2021-07-01T00:00:00Z", MethodId: 2},
+	}
+
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(items))
+
+	report, err := client.AggregateFees(feeTable(), lvlup.PaymentsFilter{
+		To: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, report.Methods, 2)
+	assert.Equal(t, 2, report.Methods[0].Count)
+	assert.Equal(t, 60, report.Methods[0].FeePlnInt)
+	assert.Equal(t, lvlup.PaymentMethod(9), report.Methods[1].Method)
+	assert.Equal(t, 100, report.Methods[1].FeePlnInt)
+	assert.Equal(t, 3, report.Total.Count)
+	assert.Equal(t, 4000, report.Total.GrossPlnInt)
+	assert.Equal(t, 3840, report.Total.NetPlnInt)
+}
+
+func Test_aggregate_fees_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+
+	_, err := client.AggregateFees(feeTable(), lvlup.PaymentsFilter{})
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
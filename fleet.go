@@ -0,0 +1,262 @@
+package lvlup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FleetSnapshotOptions represents available options for FleetSnapshot func.
+type FleetSnapshotOptions struct {
+	Concurrency  int
+	AttacksSince time.Time
+}
+
+// FleetSnapshotOption represents a functional option for FleetSnapshot func.
+type FleetSnapshotOption func(*FleetSnapshotOptions)
+
+// WithConcurrency sets how many VPSes are inspected at the same time.
+func WithConcurrency(concurrency int) FleetSnapshotOption {
+	return func(fso *FleetSnapshotOptions) {
+		fso.Concurrency = concurrency
+	}
+}
+
+// WithAttacksSince limits reported DDoS attacks to ones started after specified time.
+func WithAttacksSince(since time.Time) FleetSnapshotOption {
+	return func(fso *FleetSnapshotOptions) {
+		fso.AttacksSince = since
+	}
+}
+
+// FleetError represents a single failed call made while building a fleet snapshot.
+type FleetError struct {
+	VPSId     VPSID  `json:"vpsId"`
+	Operation string `json:"operation"`
+	Message   string `json:"message"`
+	Err       error  `json:"-"`
+}
+
+// Error implements error interface.
+func (fe FleetError) Error() string {
+	return fmt.Sprintf("vps %s: %s: %s", fe.VPSId, fe.Operation, fe.Message)
+}
+
+// Unwrap returns the underlying error.
+func (fe FleetError) Unwrap() error {
+	return fe.Err
+}
+
+// FleetVPS represents details gathered for a single VPS.
+type FleetVPS struct {
+	Service    Service              `json:"service"`
+	State      *GetVPSStateResult   `json:"state,omitempty"`
+	Filter     *GetUDPFilterResult  `json:"filter,omitempty"`
+	Exceptions []UDPFilterException `json:"exceptions,omitempty"`
+	Attacks    []DDoSAttack         `json:"attacks,omitempty"`
+	Errors     []FleetError         `json:"errors,omitempty"`
+}
+
+// FleetReport represents result of FleetSnapshot func.
+type FleetReport struct {
+	GeneratedAt time.Time    `json:"generatedAt"`
+	VPS         []FleetVPS   `json:"vps"`
+	Errors      []FleetError `json:"errors,omitempty"`
+}
+
+// Partial reports whether any of the calls made for the snapshot failed.
+func (fr *FleetReport) Partial() bool {
+	return len(fr.Errors) > 0
+}
+
+// WriteJSON writes the report to w as json.
+func (fr *FleetReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(fr)
+}
+
+// WriteCSV writes the report to w as csv, one row per VPS.
+func (fr *FleetReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{
+		"id", "name", "plan", "ip", "active", "payedTo",
+		"status", "uptimeS", "filteringEnabled", "filterState",
+		"exceptions", "attacks", "errors",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, vps := range fr.VPS {
+		var status, uptime, filteringEnabled, filterState string
+
+		if vps.State != nil {
+			status = vps.State.Status
+			uptime = strconv.Itoa(vps.State.VmUptimeS)
+		}
+
+		if vps.Filter != nil {
+			filteringEnabled = strconv.FormatBool(vps.Filter.FilteringEnabled)
+			filterState = vps.Filter.State
+		}
+
+		messages := make([]string, 0, len(vps.Errors))
+		for _, fe := range vps.Errors {
+			messages = append(messages, fe.Operation+": "+fe.Message)
+		}
+
+		row := []string{
+			vps.Service.Id.String(),
+			vps.Service.Name,
+			vps.Service.PlanName,
+			vps.Service.Ip,
+			strconv.FormatBool(vps.Service.Active),
+			vps.Service.PayedTo,
+			status,
+			uptime,
+			filteringEnabled,
+			filterState,
+			strconv.Itoa(len(vps.Exceptions)),
+			strconv.Itoa(len(vps.Attacks)),
+			strings.Join(messages, "; "),
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// FleetSnapshot allows to gather state, UDP filter configuration and DDoS attacks of all VPSes.
+// Failures of per-VPS calls are reported in the result instead of aborting the snapshot.
+// Requests are sent with ctx, so cancelling it aborts calls in flight.
+// It returns the report and any errors encountered while listing services.
+func (lc LvlClient) FleetSnapshot(ctx context.Context, opts ...FleetSnapshotOption) (*FleetReport, error) {
+	client := lc.WithContext(ctx)
+
+	options := &FleetSnapshotOptions{
+		Concurrency: 4,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	services, err := client.ListServices(WithKind(KindVPS))
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FleetReport{
+		GeneratedAt: time.Now(),
+	}
+
+	for _, service := range services.Services {
+		report.VPS = append(report.VPS, FleetVPS{Service: service})
+	}
+
+	jobs := make(chan *FleetVPS)
+	var wg sync.WaitGroup
+
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for vps := range jobs {
+				client.inspectFleetVPS(ctx, vps, options)
+			}
+		}()
+	}
+
+	for i := range report.VPS {
+		jobs <- &report.VPS[i]
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for _, vps := range report.VPS {
+		report.Errors = append(report.Errors, vps.Errors...)
+	}
+
+	return report, ctx.Err()
+}
+
+// inspectFleetVPS fills the entry with details fetched from the api.
+func (lc LvlClient) inspectFleetVPS(ctx context.Context, vps *FleetVPS, options *FleetSnapshotOptions) {
+	vpsId := vps.Service.Id.VPS()
+	vpsClient := lc.VPS(vpsId)
+
+	fail := func(operation string, err error) {
+		vps.Errors = append(vps.Errors, FleetError{
+			VPSId:     vpsId,
+			Operation: operation,
+			Message:   err.Error(),
+			Err:       err,
+		})
+	}
+
+	steps := []struct {
+		operation string
+		fetch     func() error
+	}{
+		{"GetVPSState", func() (err error) {
+			vps.State, err = vpsClient.State()
+			return err
+		}},
+		{"GetUDPFilter", func() (err error) {
+			vps.Filter, err = vpsClient.Filter().Get()
+			return err
+		}},
+		{"ListUDPFilterExceptions", func() (err error) {
+			vps.Exceptions, err = vpsClient.Filter().Exceptions()
+			return err
+		}},
+		{"ListDDoSAttacks", func() error {
+			attacks, err := vpsClient.Attacks()
+
+			if err != nil {
+				return err
+			}
+
+			for _, attack := range attacks.Items {
+				if options.AttacksSince.IsZero() || int64(attack.StartedAt) >= options.AttacksSince.Unix() {
+					vps.Attacks = append(vps.Attacks, attack)
+				}
+			}
+
+			return nil
+		}},
+	}
+
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			fail(step.operation, err)
+			continue
+		}
+
+		if err := step.fetch(); err != nil {
+			fail(step.operation, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package lvlup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fleetHandler() testutil.RoundTripFunc {
+	return testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/services": testutil.JSON(http.StatusOK, lvlup.ListServicesResult{
+			Services: []lvlup.Service{
+				{Id: 1, PlanName: "VPS Start", Active: true},
+				{Id: 2, PlanName: "Domena .pl", Active: true},
+				{Id: 3, PlanName: "VPS Pro", Active: true},
+			},
+		}),
+		"/v4/services/vps/1/state":               testutil.JSON(http.StatusOK, lvlup.GetVPSStateResult{Status: "running", VmUptimeS: 60}),
+		"/v4/services/vps/1/filtering":           testutil.JSON(http.StatusOK, lvlup.GetUDPFilterResult{FilteringEnabled: true}),
+		"/v4/services/vps/1/filtering/whitelist": testutil.JSON(http.StatusOK, []lvlup.UDPFilterException{{Id: 1}}),
+		"/v4/services/vps/1/attacks": testutil.JSON(http.StatusOK, lvlup.ListDDoSAttacksResult{
+			Count: 2,
+			Items: []lvlup.DDoSAttack{{Id: 1, StartedAt: 100}, {Id: 2, StartedAt: 2000}},
+		}),
+		"/v4/services/vps/3/state":               testutil.HttpError(http.StatusInternalServerError),
+		"/v4/services/vps/3/filtering":           testutil.JSON(http.StatusOK, lvlup.GetUDPFilterResult{}),
+		"/v4/services/vps/3/filtering/whitelist": testutil.JSON(http.StatusOK, []lvlup.UDPFilterException{}),
+		"/v4/services/vps/3/attacks":             testutil.JSON(http.StatusOK, lvlup.ListDDoSAttacksResult{}),
+	})
+}
+
+func Test_fleet_snapshot(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", fleetHandler())
+
+	report, err := client.FleetSnapshot(context.Background(), lvlup.WithAttacksSince(time.Unix(1000, 0)))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, report.VPS, 2)
+
+	first := report.VPS[0]
+	assert.Equal(t, "running", first.State.Status)
+	assert.True(t, first.Filter.FilteringEnabled)
+	assert.Len(t, first.Exceptions, 1)
+	assert.Len(t, first.Attacks, 1)
+	assert.Empty(t, first.Errors)
+}
+
+func Test_fleet_snapshot_partial_failure(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", fleetHandler())
+
+	report, err := client.FleetSnapshot(context.Background())
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.True(t, report.Partial())
+	assert.Len(t, report.Errors, 1)
+	assert.Equal(t, lvlup.VPSID(3), report.Errors[0].VPSId)
+	assert.Equal(t, "GetVPSState", report.Errors[0].Operation)
+	assert.Nil(t, report.VPS[1].State)
+	assert.NotNil(t, report.VPS[1].Filter)
+}
+
+func Test_fleet_snapshot_list_services_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+
+	_, err := client.FleetSnapshot(context.Background())
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
+
+func Test_fleet_snapshot_canceled(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", fleetHandler())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := client.FleetSnapshot(ctx)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, report, "Report should be nil when services can't be listed")
+}
+
+func Test_fleet_snapshot_canceled_during_calls(t *testing.T) {
+	handler := fleetHandler()
+	client := testutil.NewTestLvlClient("token", func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/v4/services" {
+			return handler(r)
+		}
+
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	report, err := client.FleetSnapshot(ctx)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second), "Blocked calls should be aborted")
+	assert.NotEmpty(t, report.Errors, "Aborted calls should be reported")
+
+	for _, failure := range report.Errors {
+		assert.True(t, errors.Is(failure.Err, context.Canceled), "Error should be context.Canceled")
+	}
+}
+
+func Test_fleet_report_export(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", fleetHandler())
+
+	report, err := client.FleetSnapshot(context.Background())
+	assert.Nil(t, err, "Error should be nil")
+
+	var jsonOut bytes.Buffer
+	assert.Nil(t, report.WriteJSON(&jsonOut))
+
+	var decoded lvlup.FleetReport
+	assert.Nil(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Len(t, decoded.VPS, 2)
+
+	var csvOut bytes.Buffer
+	assert.Nil(t, report.WriteCSV(&csvOut))
+
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "running", rows[1][6])
+	assert.Contains(t, rows[2][12], "GetVPSState")
+}
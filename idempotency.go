@@ -0,0 +1,134 @@
+package lvlup
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOutcomeUnknown is returned by CreatePayment when an earlier request with the same
+// idempotency key failed in a way which doesn't tell whether the payment was created,
+// for example with a timeout. Check the payments list before creating the payment again.
+var ErrOutcomeUnknown = errors.New("payment outcome unknown")
+
+// IdempotencyStore describes storage of payments created with an idempotency key.
+// Reserve is called before a payment is created and has to fail for keys which are
+// already reserved or have a result, so a key is never sent twice. Release is called
+// when the api rejected the request, so the payment surely wasn't created.
+type IdempotencyStore interface {
+	Get(key string) (*CreatePaymentResult, bool)
+	Put(key string, result *CreatePaymentResult)
+	Reserve(key string) bool
+	Release(key string)
+}
+
+// idempotencyEntry represents a single stored result.
+// Entries without result are reserved for requests in flight or with unknown outcome.
+type idempotencyEntry struct {
+	result    *CreatePaymentResult
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore represents IdempotencyStore keeping results in memory for a limited time.
+type MemoryIdempotencyStore struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]idempotencyEntry
+}
+
+// defaultIdempotencyTTL is how long results and reservations are kept if no valid ttl is set.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// NewMemoryIdempotencyStore creates new store keeping results and reservations for ttl.
+// Non-positive ttl is replaced with 24 hours, as it would make every entry expire at once.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		entries: map[string]idempotencyEntry{},
+	}
+}
+
+// Get returns result stored with key, if it did not expire yet.
+func (mis *MemoryIdempotencyStore) Get(key string) (*CreatePaymentResult, bool) {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	entry, ok := mis.entry(key, time.Now())
+
+	if !ok || entry.result == nil {
+		return nil, false
+	}
+
+	result := *entry.result
+	return &result, true
+}
+
+// Put stores result with key.
+func (mis *MemoryIdempotencyStore) Put(key string, result *CreatePaymentResult) {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	stored := *result
+	mis.set(key, &stored)
+}
+
+// Reserve marks key as used by a request in flight.
+// It returns false if the key is already reserved or has a result.
+func (mis *MemoryIdempotencyStore) Reserve(key string) bool {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	if _, ok := mis.entry(key, time.Now()); ok {
+		return false
+	}
+
+	mis.set(key, nil)
+
+	return true
+}
+
+// Release removes reservation of key. Stored results are kept.
+func (mis *MemoryIdempotencyStore) Release(key string) {
+	mis.mu.Lock()
+	defer mis.mu.Unlock()
+
+	if entry, ok := mis.entries[key]; ok && entry.result == nil {
+		delete(mis.entries, key)
+	}
+}
+
+// entry returns entry stored with key, if it did not expire yet.
+func (mis *MemoryIdempotencyStore) entry(key string, now time.Time) (idempotencyEntry, bool) {
+	entry, ok := mis.entries[key]
+
+	if !ok {
+		return idempotencyEntry{}, false
+	}
+
+	if now.After(entry.expiresAt) {
+		delete(mis.entries, key)
+		return idempotencyEntry{}, false
+	}
+
+	return entry, true
+}
+
+// set stores entry with key. Expired entries are removed on the way.
+func (mis *MemoryIdempotencyStore) set(key string, result *CreatePaymentResult) {
+	now := time.Now()
+
+	for k, entry := range mis.entries {
+		if now.After(entry.expiresAt) {
+			delete(mis.entries, k)
+		}
+	}
+
+	mis.entries[key] = idempotencyEntry{
+		result:    result,
+		expiresAt: now.Add(mis.ttl),
+	}
+}
@@ -0,0 +1,150 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// idempotentServer simulates api creating one payment per idempotency key.
+// The first request for every key times out after the payment is created.
+func idempotentServer(requests *int) testutil.RoundTripFunc {
+	created := map[string]lvlup.CreatePaymentResult{}
+
+	return func(r *http.Request) (*http.Response, error) {
+		*requests++
+		key := r.Header.Get("Idempotency-Key")
+
+		if result, ok := created[key]; ok {
+			return testutil.JSON(http.StatusOK, result)(r)
+		}
+
+		created[key] = lvlup.CreatePaymentResult{
+			Id:  lvlup.PaymentID("payment-" + strconv.Itoa(len(created)+1)),
+			Url: "https://pay.example",
+		}
+
+		return nil, errors.New("timeout")
+	}
+}
+
+func Test_create_payment_retry_after_timeout(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", idempotentServer(&requests))
+
+	_, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.NotNil(t, err, "Error should not be nil")
+
+	result, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentID("payment-1"), result.Id)
+	assert.Equal(t, 2, requests)
+}
+
+func Test_create_payment_retry_after_timeout_with_store(t *testing.T) {
+	requests := 0
+	handler := func(r *http.Request) (*http.Response, error) {
+		requests++
+
+		// The api ignores the header, so every request creates a payment.
+		if requests == 1 {
+			return nil, errors.New("timeout")
+		}
+
+		return testutil.JSON(http.StatusOK, lvlup.CreatePaymentResult{Id: lvlup.PaymentID("payment-" + strconv.Itoa(requests))})(r)
+	}
+
+	client := testutil.NewTestLvlClient(
+		"token",
+		handler,
+		lvlup.WithIdempotencyStore(lvlup.NewMemoryIdempotencyStore(time.Hour)),
+	)
+
+	_, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.NotNil(t, err, "Error should not be nil")
+
+	_, err = client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.True(t, errors.Is(err, lvlup.ErrOutcomeUnknown), "Error should be ErrOutcomeUnknown")
+	assert.Equal(t, 1, requests, "Payment should not be created again")
+}
+
+func Test_create_payment_rejected_releases_key(t *testing.T) {
+	requests := 0
+	handler := func(r *http.Request) (*http.Response, error) {
+		requests++
+
+		if requests == 1 {
+			return testutil.HttpError(http.StatusBadRequest)(r)
+		}
+
+		return testutil.JSON(http.StatusOK, lvlup.CreatePaymentResult{Id: "payment-1"})(r)
+	}
+
+	client := testutil.NewTestLvlClient(
+		"token",
+		handler,
+		lvlup.WithIdempotencyStore(lvlup.NewMemoryIdempotencyStore(time.Hour)),
+	)
+
+	_, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.NotNil(t, err, "Error should not be nil")
+
+	result, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentID("payment-1"), result.Id)
+}
+
+func Test_create_payment_with_idempotency_store(t *testing.T) {
+	requests := 0
+	handler := func(r *http.Request) (*http.Response, error) {
+		requests++
+		return testutil.JSON(http.StatusOK, lvlup.CreatePaymentResult{Id: lvlup.PaymentID("payment-" + strconv.Itoa(requests))})(r)
+	}
+
+	client := testutil.NewTestLvlClient(
+		"token",
+		handler,
+		lvlup.WithIdempotencyStore(lvlup.NewMemoryIdempotencyStore(time.Hour)),
+	)
+
+	first, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.Nil(t, err, "Error should be nil")
+
+	second, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-1"))
+	assert.Nil(t, err, "Error should be nil")
+
+	other, err := client.CreatePayment("10.00", lvlup.WithIdempotencyKey("order-2"))
+	assert.Nil(t, err, "Error should be nil")
+
+	assert.Equal(t, first.Id, second.Id)
+	assert.NotEqual(t, first.Id, other.Id)
+	assert.Equal(t, 2, requests)
+}
+
+func Test_memory_idempotency_store_ttl(t *testing.T) {
+	store := lvlup.NewMemoryIdempotencyStore(50 * time.Millisecond)
+	store.Put("key", &lvlup.CreatePaymentResult{Id: "1"})
+
+	result, ok := store.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, lvlup.PaymentID("1"), result.Id)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, ok = store.Get("key")
+	assert.False(t, ok, "Entry should expire")
+}
+
+func Test_memory_idempotency_store_invalid_ttl(t *testing.T) {
+	store := lvlup.NewMemoryIdempotencyStore(0)
+
+	assert.True(t, store.Reserve("order-1"), "First reservation should succeed")
+	assert.False(t, store.Reserve("order-1"), "Key should stay reserved")
+}
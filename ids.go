@@ -0,0 +1,190 @@
+package lvlup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidID is returned when provided id can't be used in a request path.
+var ErrInvalidID = errors.New("invalid id")
+
+// validateID checks if id is a non-empty decimal number.
+func validateID(kind string, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: empty %s id", ErrInvalidID, kind)
+	}
+
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%w: %s id %q", ErrInvalidID, kind, id)
+		}
+	}
+
+	return nil
+}
+
+// PaymentID represents id of a payment created with CreatePayment.
+type PaymentID string
+
+// ParsePaymentID allows to parse payment id, for example from a url.
+func ParsePaymentID(value string) (PaymentID, error) {
+	if value == "" {
+		return "", fmt.Errorf("%w: empty payment id", ErrInvalidID)
+	}
+
+	return PaymentID(value), nil
+}
+
+// String returns the id as string.
+func (id PaymentID) String() string {
+	return string(id)
+}
+
+// UnmarshalJSON accepts the id encoded both as json string and number.
+func (id *PaymentID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '"' && !bytes.Equal(data, []byte("null")) {
+		var number json.Number
+
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+
+		*id = PaymentID(number.String())
+		return nil
+	}
+
+	var value string
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*id = PaymentID(value)
+	return nil
+}
+
+// PaymentItemID represents id of a payment listed by ListPayments.
+// It's a different id than PaymentID returned by CreatePayment.
+type PaymentItemID int
+
+// ParsePaymentItemID allows to parse listed payment id, for example from a cursor file.
+func ParsePaymentItemID(value string) (PaymentItemID, error) {
+	id, err := parseNumericID("payment item", value)
+	return PaymentItemID(id), err
+}
+
+// String returns the id as decimal string.
+func (id PaymentItemID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// UnmarshalJSON accepts the id encoded both as json number and string.
+func (id *PaymentItemID) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalNumericID(data)
+	*id = PaymentItemID(value)
+	return err
+}
+
+// ServiceID represents id of a service like VPS or domain.
+type ServiceID int
+
+// ParseServiceID allows to parse service id, for example from a url.
+func ParseServiceID(value string) (ServiceID, error) {
+	id, err := parseNumericID("service", value)
+	return ServiceID(id), err
+}
+
+// String returns the id as decimal string.
+func (id ServiceID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// UnmarshalJSON accepts the id encoded both as json number and string.
+func (id *ServiceID) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalNumericID(data)
+	*id = ServiceID(value)
+	return err
+}
+
+// VPS returns id of the VPS, if the service is a VPS.
+func (id ServiceID) VPS() VPSID {
+	return VPSID(id)
+}
+
+// VPSID represents id of a VPS. It's the id of the VPS service.
+type VPSID int
+
+// ParseVPSID allows to parse VPS id, for example from a url.
+func ParseVPSID(value string) (VPSID, error) {
+	id, err := parseNumericID("vps", value)
+	return VPSID(id), err
+}
+
+// String returns the id as decimal string.
+func (id VPSID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// UnmarshalJSON accepts the id encoded both as json number and string.
+func (id *VPSID) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalNumericID(data)
+	*id = VPSID(value)
+	return err
+}
+
+// FilterExceptionID represents id of an UDP filter exception.
+type FilterExceptionID int
+
+// ParseFilterExceptionID allows to parse UDP filter exception id, for example from a url.
+func ParseFilterExceptionID(value string) (FilterExceptionID, error) {
+	id, err := parseNumericID("exception", value)
+	return FilterExceptionID(id), err
+}
+
+// String returns the id as decimal string.
+func (id FilterExceptionID) String() string {
+	return strconv.Itoa(int(id))
+}
+
+// UnmarshalJSON accepts the id encoded both as json number and string.
+func (id *FilterExceptionID) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalNumericID(data)
+	*id = FilterExceptionID(value)
+	return err
+}
+
+// parseNumericID parses positive decimal id.
+func parseNumericID(kind string, value string) (int, error) {
+	if err := validateID(kind, value); err != nil {
+		return 0, err
+	}
+
+	id, err := strconv.Atoi(value)
+
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: %s id %q", ErrInvalidID, kind, value)
+	}
+
+	return id, nil
+}
+
+// unmarshalNumericID decodes id encoded as json number or string.
+func unmarshalNumericID(data []byte) (int, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var value string
+
+		if err := json.Unmarshal(data, &value); err != nil {
+			return 0, err
+		}
+
+		return strconv.Atoi(value)
+	}
+
+	var value int
+	err := json.Unmarshal(data, &value)
+
+	return value, err
+}
@@ -0,0 +1,68 @@
+package lvlup_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/senicko/lvlup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parse_ids(t *testing.T) {
+	vpsId, err := lvlup.ParseVPSID("12")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.VPSID(12), vpsId)
+	assert.Equal(t, "12", vpsId.String())
+
+	serviceId, err := lvlup.ParseServiceID("5")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.VPSID(5), serviceId.VPS())
+
+	itemId, err := lvlup.ParsePaymentItemID("42")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentItemID(42), itemId)
+
+	paymentId, err := lvlup.ParsePaymentID("abc")
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "abc", paymentId.String())
+}
+
+func Test_parse_invalid_ids(t *testing.T) {
+	for _, value := range []string{"", "0", "abc", "1/../2", "-1"} {
+		_, err := lvlup.ParseVPSID(value)
+		assert.True(t, errors.Is(err, lvlup.ErrInvalidID), "Error should be ErrInvalidID for %q", value)
+
+		_, err = lvlup.ParseFilterExceptionID(value)
+		assert.True(t, errors.Is(err, lvlup.ErrInvalidID), "Error should be ErrInvalidID for %q", value)
+	}
+
+	_, err := lvlup.ParsePaymentID("")
+	assert.True(t, errors.Is(err, lvlup.ErrInvalidID), "Error should be ErrInvalidID")
+}
+
+func Test_unmarshal_ids(t *testing.T) {
+	var result struct {
+		Service   lvlup.ServiceID         `json:"service"`
+		Quoted    lvlup.ServiceID         `json:"quoted"`
+		Exception lvlup.FilterExceptionID `json:"exception"`
+		Payment   lvlup.PaymentID         `json:"payment"`
+		Numeric   lvlup.PaymentID         `json:"numeric"`
+		Item      lvlup.PaymentItemID     `json:"item"`
+	}
+
+	err := json.Unmarshal([]byte(`{"service":1,"quoted":"2","exception":3,"payment":"p4","numeric":5,"item":6}`), &result)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.ServiceID(1), result.Service)
+	assert.Equal(t, lvlup.ServiceID(2), result.Quoted)
+	assert.Equal(t, lvlup.FilterExceptionID(3), result.Exception)
+	assert.Equal(t, lvlup.PaymentID("p4"), result.Payment)
+	assert.Equal(t, lvlup.PaymentID("5"), result.Numeric)
+	assert.Equal(t, lvlup.PaymentItemID(6), result.Item)
+}
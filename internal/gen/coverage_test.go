@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_covered_methods_exist(t *testing.T) {
+	declarations, err := scanPackage("../..")
+	assert.Nil(t, err, "Error should be nil")
+
+	for endpoint, method := range covered {
+		assert.True(t, declarations.Methods[method], "Method %s covering %s should exist", method, endpoint)
+	}
+}
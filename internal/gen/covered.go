@@ -0,0 +1,131 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// covered maps endpoints implemented by hand to methods exposing them.
+// Generated code skips these endpoints, so they have to be kept up to date
+// when hand-written methods are added.
+var covered = map[string]string{
+	"POST /wallet/up":                                "LvlClient.CreatePayment",
+	"GET /wallet/up/{}":                              "LvlClient.InspectPayment",
+	"GET /wallet":                                    "LvlClient.WalletBalance",
+	"GET /payments":                                  "LvlClient.ListPayments",
+	"GET /services":                                  "LvlClient.ListServices",
+	"POST /services/vps/{}/start":                    "VPSClient.Start",
+	"POST /services/vps/{}/stop":                     "VPSClient.Stop",
+	"GET /services/vps/{}/state":                     "VPSClient.State",
+	"GET /services/vps/{}/attacks":                   "VPSClient.Attacks",
+	"POST /services/vps/{}/proxmo":                   "VPSClient.Proxmo",
+	"GET /services/vps/{}/filtering":                 "VPSFilterClient.Get",
+	"PUT /services/vps/{}/filtering":                 "VPSFilterClient.Set",
+	"GET /services/vps/{}/filtering/whitelist":       "VPSFilterClient.Exceptions",
+	"POST /services/vps/{}/filtering/whitelist":      "VPSFilterClient.AddException",
+	"DELETE /services/vps/{}/filtering/whitelist/{}": "VPSFilterClient.RemoveException",
+}
+
+// endpointDirective marks generated methods with the endpoint they call.
+const endpointDirective = "//lvlup:endpoint "
+
+// Declarations represents names declared in the client package.
+type Declarations struct {
+	Types     map[string]bool
+	Methods   map[string]bool
+	Endpoints map[string]string
+}
+
+// scanPackage collects types, methods and generated endpoints declared in dir.
+// Test files and excluded files are ignored.
+func scanPackage(dir string, exclude ...string) (*Declarations, error) {
+	fset := token.NewFileSet()
+	filter := func(info os.FileInfo) bool {
+		for _, name := range exclude {
+			if info.Name() == name {
+				return false
+			}
+		}
+
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+
+	packages, err := parser.ParseDir(fset, dir, filter, parser.ParseComments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	declarations := &Declarations{
+		Types:     map[string]bool{},
+		Methods:   map[string]bool{},
+		Endpoints: map[string]string{},
+	}
+
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				declarations.add(decl)
+			}
+		}
+	}
+
+	return declarations, nil
+}
+
+// add records a single declaration.
+func (d *Declarations) add(decl ast.Decl) {
+	switch decl := decl.(type) {
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			if spec, ok := spec.(*ast.TypeSpec); ok {
+				d.Types[spec.Name.Name] = true
+			}
+		}
+	case *ast.FuncDecl:
+		if decl.Recv == nil || len(decl.Recv.List) == 0 {
+			return
+		}
+
+		receiver := decl.Recv.List[0].Type
+		if star, ok := receiver.(*ast.StarExpr); ok {
+			receiver = star.X
+		}
+
+		ident, ok := receiver.(*ast.Ident)
+
+		if !ok {
+			return
+		}
+
+		name := ident.Name + "." + decl.Name.Name
+		d.Methods[name] = true
+
+		if decl.Doc == nil {
+			return
+		}
+
+		for _, comment := range decl.Doc.List {
+			if strings.HasPrefix(comment.Text, endpointDirective) {
+				fields := strings.Fields(strings.TrimPrefix(comment.Text, endpointDirective))
+
+				if len(fields) == 2 {
+					d.Endpoints[endpointKey(fields[0], fields[1])] = name
+				}
+			}
+		}
+	}
+}
+
+// Exposes returns method exposing the endpoint, either hand-written or generated.
+func (d *Declarations) Exposes(endpoint Endpoint) (string, bool) {
+	if method, ok := covered[endpoint.Key()]; ok && d.Methods[method] {
+		return method, true
+	}
+
+	method, ok := d.Endpoints[endpoint.Key()]
+	return method, ok
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// generator writes types and methods for endpoints not implemented by hand.
+type generator struct {
+	spec     *Spec
+	existing *Declarations
+	buf      bytes.Buffer
+	imports  map[string]bool
+}
+
+// Generate builds source of the generated file for package pkg.
+// Types and methods already declared in the package are not generated again.
+func Generate(spec *Spec, existing *Declarations, pkg string, source string) ([]byte, error) {
+	g := &generator{
+		spec:     spec,
+		existing: existing,
+		imports:  map[string]bool{},
+	}
+
+	g.types()
+	g.methods()
+
+	var file bytes.Buffer
+
+	fmt.Fprintf(&file, "// Code generated by internal/gen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&file, "package %s\n\n", pkg)
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for name := range g.imports {
+			imports = append(imports, strconv.Quote(name))
+		}
+
+		sort.Strings(imports)
+		fmt.Fprintf(&file, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	file.Write(g.buf.Bytes())
+
+	return format.Source(file.Bytes())
+}
+
+// types writes structs for component schemas.
+func (g *generator) types() {
+	names := make([]string, 0, len(g.spec.Components.Schemas))
+	for name := range g.spec.Components.Schemas {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		typeName := exported(name)
+
+		if g.existing.Types[typeName] {
+			continue
+		}
+
+		schema := g.spec.Components.Schemas[name]
+
+		if schema.Description != "" {
+			fmt.Fprintf(&g.buf, "// %s %s\n", typeName, lowerFirst(schema.Description))
+		} else {
+			fmt.Fprintf(&g.buf, "// %s represents %s schema of the api.\n", typeName, name)
+		}
+
+		if schema.Type != "object" || len(schema.Properties) == 0 {
+			fmt.Fprintf(&g.buf, "type %s %s\n\n", typeName, g.goType(schema))
+			continue
+		}
+
+		fmt.Fprintf(&g.buf, "type %s struct {\n", typeName)
+
+		properties := make([]string, 0, len(schema.Properties))
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+
+		sort.Strings(properties)
+
+		for _, property := range properties {
+			fmt.Fprintf(&g.buf, "%s %s `json:%q`\n", exported(property), g.goType(schema.Properties[property]), property)
+		}
+
+		g.buf.WriteString("}\n\n")
+	}
+}
+
+// methods writes LvlClient methods for endpoints without hand-written counterpart.
+func (g *generator) methods() {
+	for _, endpoint := range g.spec.Endpoints() {
+		if _, ok := covered[endpoint.Key()]; ok {
+			continue
+		}
+
+		name := methodName(endpoint)
+
+		if g.existing.Methods["LvlClient."+name] {
+			continue
+		}
+
+		g.method(name, endpoint)
+	}
+}
+
+// idTypes maps path parameters to id types of the client package.
+// Other path parameters are passed as strings.
+var idTypes = map[string]string{
+	"paymentId":   "PaymentID",
+	"serviceId":   "ServiceID",
+	"vpsId":       "VPSID",
+	"exceptionId": "FilterExceptionID",
+}
+
+// method writes a single method calling the endpoint.
+func (g *generator) method(name string, endpoint Endpoint) {
+	operation := endpoint.Operation
+
+	var params, pathArgs []string
+	var query bool
+
+	for _, segment := range strings.Split(strings.Trim(endpoint.Path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			param := unexported(strings.Trim(segment, "{}"))
+
+			if idType, ok := idTypes[param]; ok {
+				params = append(params, param+" "+idType)
+				pathArgs = append(pathArgs, param+".String()")
+			} else {
+				params = append(params, param+" string")
+				pathArgs = append(pathArgs, param)
+			}
+		} else {
+			pathArgs = append(pathArgs, strconv.Quote(segment))
+		}
+	}
+
+	for _, parameter := range operation.Parameters {
+		if parameter.In == "query" {
+			query = true
+		}
+	}
+
+	if query {
+		params = append(params, "query map[string]string")
+	}
+
+	body := operation.RequestBody.jsonSchema()
+	if body != nil {
+		params = append(params, "body "+g.pointerType(body))
+	}
+
+	status, result := successResponse(operation)
+	var resultType string
+	if result != nil {
+		resultType = g.pointerType(result)
+	}
+
+	g.imports["net/http"] = true
+
+	if operation.Summary != "" {
+		fmt.Fprintf(&g.buf, "// %s allows to %s\n", name, lowerFirst(strings.TrimSuffix(operation.Summary, ".")+"."))
+	} else {
+		fmt.Fprintf(&g.buf, "// %s allows to call %s %s.\n", name, endpoint.Method, endpoint.Path)
+	}
+
+	if resultType != "" {
+		g.buf.WriteString("// It returns result of a request and any errors encountered.\n")
+	}
+
+	fmt.Fprintf(&g.buf, "%s%s %s\n", endpointDirective, endpoint.Method, endpoint.Path)
+
+	if resultType != "" {
+		fmt.Fprintf(&g.buf, "func (lc LvlClient) %s(%s) (%s, error) {\n", name, strings.Join(params, ", "), resultType)
+	} else {
+		fmt.Fprintf(&g.buf, "func (lc LvlClient) %s(%s) error {\n", name, strings.Join(params, ", "))
+	}
+
+	descriptor := fmt.Sprintf("operation{Name: %q, Method: %s, Path: buildPath(%s)", name, methodConstant(endpoint.Method), strings.Join(pathArgs, ", "))
+	if status != http.StatusOK {
+		descriptor += ", Status: " + statusConstant(status)
+	}
+	descriptor += "}"
+
+	args := []string{descriptor, "nil", "nil"}
+
+	if body != nil {
+		args[1] = "body"
+	}
+
+	if query {
+		args = append(args, "withQuery(query)")
+	}
+
+	if resultType == "" {
+		fmt.Fprintf(&g.buf, "return lc.call(\n%s,\n)\n}\n\n", strings.Join(args, ",\n"))
+		return
+	}
+
+	returned := "result"
+	if strings.HasPrefix(resultType, "*") {
+		returned = "&result"
+	}
+
+	args[2] = "&result"
+	fmt.Fprintf(&g.buf, "var result %s\nif err := lc.call(\n%s,\n); err != nil {\nreturn nil, err\n}\n\nreturn %s, nil\n}\n\n",
+		strings.TrimPrefix(resultType, "*"), strings.Join(args, ",\n"), returned)
+}
+
+// goType returns go type representing the schema.
+func (g *generator) goType(schema *Schema) string {
+	if schema == nil {
+		return "interface{}"
+	}
+
+	if schema.Ref != "" {
+		return exported(schema.Ref[strings.LastIndex(schema.Ref, "/")+1:])
+	}
+
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(schema.Items)
+	case "object":
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+}
+
+// pointerType returns type used for request bodies and results.
+// Slices and maps are used directly, other types through a pointer.
+func (g *generator) pointerType(schema *Schema) string {
+	goType := g.goType(schema)
+
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") {
+		return goType
+	}
+
+	return "*" + goType
+}
+
+// successResponse returns status and schema of the first 2xx response.
+func successResponse(operation *Operation) (int, *Schema) {
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		status, err := strconv.Atoi(code)
+
+		if err != nil || status < 200 || status > 299 {
+			continue
+		}
+
+		return status, operation.Responses[code].jsonSchema()
+	}
+
+	return http.StatusOK, nil
+}
+
+// methodName returns name of a generated method.
+// It uses operationId if present, otherwise it's built from method and path.
+func methodName(endpoint Endpoint) string {
+	if endpoint.Operation.OperationId != "" {
+		return exported(endpoint.Operation.OperationId)
+	}
+
+	name := exported(strings.ToLower(endpoint.Method))
+
+	for _, segment := range strings.Split(endpoint.Path, "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+
+		name += exported(segment)
+	}
+
+	return name
+}
+
+// exported converts identifiers like vps-state or vps_state to VpsState.
+func exported(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// unexported converts identifiers like vps-id to vpsId.
+func unexported(name string) string {
+	return lowerFirst(exported(name))
+}
+
+// lowerFirst lower cases the first letter of s.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// methodConstant returns net/http constant of the method.
+func methodConstant(method string) string {
+	return "http.Method" + exported(strings.ToLower(method))
+}
+
+// statusConstant returns net/http constant of common success statuses.
+func statusConstant(status int) string {
+	switch status {
+	case http.StatusOK:
+		return "http.StatusOK"
+	case http.StatusCreated:
+		return "http.StatusCreated"
+	case http.StatusAccepted:
+		return "http.StatusAccepted"
+	case http.StatusNoContent:
+		return "http.StatusNoContent"
+	default:
+		return strconv.Itoa(status)
+	}
+}
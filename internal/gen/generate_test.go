@@ -0,0 +1,67 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_generate(t *testing.T) {
+	spec, err := loadSpec("testdata/spec.json")
+	assert.Nil(t, err, "Error should be nil")
+
+	existing, err := scanPackage("../..", "zz_generated.go")
+	assert.Nil(t, err, "Error should be nil")
+
+	source, err := Generate(spec, existing, "lvlup", "testdata/spec.json")
+	assert.Nil(t, err, "Error should be nil")
+
+	code := string(source)
+
+	assert.Contains(t, code, "// Code generated by internal/gen from testdata/spec.json. DO NOT EDIT.")
+	assert.Contains(t, code, "type Thing struct {")
+	assert.Contains(t, code, "Tags    []string `json:\"tags\"`")
+	assert.Contains(t, code, "func (lc LvlClient) GetThing(thingId string) (*Thing, error) {")
+	assert.Contains(t, code, "buildPath(\"things\", thingId)")
+	assert.Contains(t, code, "operation{Name: \"GetThing\", Method: http.MethodGet, Path: buildPath(\"things\", thingId)}")
+	assert.Contains(t, code, "func (lc LvlClient) DeleteThings(thingId string) error {")
+	assert.Contains(t, code, "Status: http.StatusNoContent}")
+	assert.Contains(t, code, "func (lc LvlClient) CreateThing(query map[string]string, body *Thing) ([]Thing, error) {")
+	assert.Contains(t, code, "//lvlup:endpoint GET /things/{thingId}")
+	assert.Contains(t, code, "func (lc LvlClient) ListBackups(vpsId VPSID) ([]string, error) {")
+	assert.Contains(t, code, "buildPath(\"services\", \"vps\", vpsId.String(), \"backups\")")
+
+	assert.NotContains(t, code, "type WalletBalanceResult", "Hand-written types should not be generated")
+	assert.NotContains(t, code, "WalletBalance(", "Hand-written methods should not be generated")
+}
+
+func Test_generated_endpoints_are_detected(t *testing.T) {
+	spec, err := loadSpec("testdata/spec.json")
+	assert.Nil(t, err, "Error should be nil")
+
+	source, err := Generate(spec, &Declarations{Types: map[string]bool{}, Methods: map[string]bool{}}, "lvlup", "testdata/spec.json")
+	assert.Nil(t, err, "Error should be nil")
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "zz_generated.go"), source, 0644)
+	assert.Nil(t, err, "Error should be nil")
+
+	_, err = parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "zz_generated.go"), nil, 0)
+	assert.Nil(t, err, "Generated code should parse")
+
+	declarations, err := scanPackage(dir)
+	assert.Nil(t, err, "Error should be nil")
+
+	for _, endpoint := range spec.Endpoints() {
+		if _, ok := covered[endpoint.Key()]; ok {
+			continue
+		}
+
+		_, ok := declarations.Exposes(endpoint)
+		assert.True(t, ok, "Endpoint %s should be exposed", endpoint.Key())
+	}
+}
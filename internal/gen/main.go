@@ -0,0 +1,48 @@
+// Command gen generates types and methods for LvlUp api endpoints from the
+// upstream v4 OpenAPI document. Endpoints implemented by hand are skipped.
+//
+// It's meant to be run from the root of the module.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	specPath := flag.String("spec", "api/openapi-v4.json", "path of the OpenAPI document in json format")
+	dir := flag.String("dir", ".", "directory of the client package")
+	out := flag.String("out", "zz_generated.go", "name of the generated file")
+	pkg := flag.String("package", "lvlup", "name of the client package")
+	flag.Parse()
+
+	if err := run(*specPath, *dir, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the file and writes it to dir.
+func run(specPath string, dir string, out string, pkg string) error {
+	spec, err := loadSpec(specPath)
+
+	if err != nil {
+		return err
+	}
+
+	existing, err := scanPackage(dir, out)
+
+	if err != nil {
+		return err
+	}
+
+	source, err := Generate(spec, existing, pkg, filepath.ToSlash(specPath))
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, out), source, 0644)
+}
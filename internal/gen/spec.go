@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Spec represents the subset of an OpenAPI 3 document used by the generator.
+type Spec struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation represents a single endpoint of the api.
+type Operation struct {
+	OperationId string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *Body                `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter represents a path or query parameter of an operation.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Body represents a json request body.
+type Body struct {
+	Content map[string]struct {
+		Schema *Schema `json:"schema"`
+	} `json:"content"`
+}
+
+// Response represents a response of an operation.
+type Response struct {
+	Description string `json:"description"`
+	Body
+}
+
+// Schema represents a json schema of a type.
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Properties  map[string]*Schema `json:"properties"`
+	Items       *Schema            `json:"items"`
+}
+
+// Endpoint represents an operation with its method and path.
+type Endpoint struct {
+	Method    string
+	Path      string
+	Operation *Operation
+}
+
+// Key returns endpoint identifier independent of path parameter names.
+func (e Endpoint) Key() string {
+	return endpointKey(e.Method, e.Path)
+}
+
+// methods lists http methods which can be used as operations in a path item.
+var methods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// pathParamRegex matches path parameters like {vpsId}.
+var pathParamRegex = regexp.MustCompile(`{[^}]*}`)
+
+// loadSpec reads and decodes OpenAPI document in json format.
+func loadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// Endpoints returns all operations of the spec sorted by path and method.
+// Paths are relative to the api base, so /v4 prefix is removed.
+func (s *Spec) Endpoints() []Endpoint {
+	var endpoints []Endpoint
+
+	for path, item := range s.Paths {
+		for _, method := range methods {
+			operation, ok := item[strings.ToLower(method)]
+
+			if !ok {
+				continue
+			}
+
+			endpoints = append(endpoints, Endpoint{
+				Method:    method,
+				Path:      strings.TrimPrefix(path, "/v4"),
+				Operation: operation,
+			})
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+
+		return endpoints[i].Method < endpoints[j].Method
+	})
+
+	return endpoints
+}
+
+// endpointKey builds endpoint identifier with path parameter names replaced by {}.
+func endpointKey(method string, path string) string {
+	return strings.ToUpper(method) + " " + pathParamRegex.ReplaceAllString(path, "{}")
+}
+
+// jsonSchema returns schema of json content, if there is any.
+func (b *Body) jsonSchema() *Schema {
+	if b == nil {
+		return nil
+	}
+
+	content, ok := b.Content["application/json"]
+
+	if !ok {
+		return nil
+	}
+
+	return content.Schema
+}
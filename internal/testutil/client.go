@@ -1,7 +1,12 @@
 package testutil
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/senicko/lvlup"
 )
@@ -13,12 +18,12 @@ func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 // NewTestLvlClient creates a new client with mocked http client.
-func NewTestLvlClient(apiKey string, handler RoundTripFunc) *lvlup.LvlClient {
+func NewTestLvlClient(apiKey string, handler RoundTripFunc, opts ...lvlup.LvlClientOption) *lvlup.LvlClient {
 	httpClient := &http.Client{
 		Transport: handler,
 	}
 
-	client := lvlup.NewLvlClient(apiKey, httpClient)
+	client := lvlup.NewLvlClient(apiKey, httpClient, opts...)
 	return client
 }
 
@@ -30,3 +35,94 @@ func HttpError(status int) RoundTripFunc {
 		}, nil
 	}
 }
+
+// JSON returns RoundTripFunc which responds with provided value encoded as json.
+func JSON(status int, v interface{}) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	}
+}
+
+// Route returns RoundTripFunc which dispatches requests to handlers by url path.
+// Requests made to unknown paths result in 404 response.
+func Route(routes map[string]RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		handler, ok := routes[req.URL.Path]
+
+		if !ok {
+			return HttpError(http.StatusNotFound)(req)
+		}
+
+		return handler(req)
+	}
+}
+
+// PaymentsPager returns RoundTripFunc which serves items like the payments endpoint.
+// Without afterId items are returned from the newest, otherwise from the oldest after afterId.
+// Both are limited by beforeId, if it's set.
+func PaymentsPager(items []lvlup.ListPaymentsResultItem) RoundTripFunc {
+	return paymentsPager(items, false)
+}
+
+// NewestFirstPaymentsPager returns RoundTripFunc which serves items like the payments endpoint,
+// always from the newest one, even when afterId is set.
+func NewestFirstPaymentsPager(items []lvlup.ListPaymentsResultItem) RoundTripFunc {
+	return paymentsPager(items, true)
+}
+
+// paymentsPager serves items between afterId and beforeId, from the newest
+// if newestFirst is set or afterId is missing, otherwise from the oldest.
+func paymentsPager(items []lvlup.ListPaymentsResultItem, newestFirst bool) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = 50
+		}
+
+		after, err := strconv.Atoi(query.Get("afterId"))
+		fromOldest := err == nil && !newestFirst
+		if err != nil {
+			after = -1
+		}
+
+		before, err := strconv.Atoi(query.Get("beforeId"))
+		if err != nil {
+			before = int(^uint(0) >> 1)
+		}
+
+		var matching []lvlup.ListPaymentsResultItem
+
+		for _, item := range items {
+			if item.Id > lvlup.PaymentItemID(after) && item.Id < lvlup.PaymentItemID(before) {
+				matching = append(matching, item)
+			}
+		}
+
+		sort.Slice(matching, func(i, j int) bool {
+			if fromOldest {
+				return matching[i].Id < matching[j].Id
+			}
+
+			return matching[i].Id > matching[j].Id
+		})
+
+		if len(matching) > limit {
+			matching = matching[:limit]
+		}
+
+		return JSON(http.StatusOK, lvlup.ListPaymentsResult{
+			Count: len(items),
+			Items: matching,
+		})(req)
+	}
+}
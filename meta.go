@@ -0,0 +1,61 @@
+package lvlup
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResponseMeta represents metadata of the last response received by a client.
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	// Body holds raw response body, including fields not modeled by result types.
+	Body []byte
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+	// Latency is the time the last attempt took until response headers were received.
+	Latency time.Duration
+}
+
+// WithResponseMeta allows to get a copy of the client which stores metadata of
+// every received response in meta. Results of methods are not affected, for example:
+//
+//	var meta lvlup.ResponseMeta
+//	balance, err := client.WithResponseMeta(&meta).WalletBalance()
+//
+// The copy overwrites meta on every request, so it should not be used concurrently.
+func (lc LvlClient) WithResponseMeta(meta *ResponseMeta) *LvlClient {
+	lc.responseMeta = meta
+	return &lc
+}
+
+// capture stores metadata of the response.
+// The body is read and replaced, so it can still be decoded by the caller.
+func (rm *ResponseMeta) capture(response *http.Response, attempts int, latency time.Duration) error {
+	var body []byte
+
+	if response.Body != nil {
+		data, err := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		if err != nil {
+			return err
+		}
+
+		body = data
+	}
+
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	*rm = ResponseMeta{
+		StatusCode: response.StatusCode,
+		Header:     response.Header.Clone(),
+		Body:       body,
+		Attempts:   attempts,
+		Latency:    latency,
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package lvlup_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_response_meta(t *testing.T) {
+	body := `{"balancePlnFormatted": "12.34", "balancePlnInt": 1234, "currency": "PLN"}`
+
+	client := testutil.NewTestLvlClient("token", func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	})
+
+	var meta lvlup.ResponseMeta
+	result, err := client.WithResponseMeta(&meta).WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1234, result.BalancePlnInt)
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+	assert.Equal(t, "req-1", meta.Header.Get("X-Request-Id"))
+	assert.Equal(t, body, string(meta.Body))
+	assert.Equal(t, 1, meta.Attempts)
+
+	var untouched lvlup.ResponseMeta
+	client.WithResponseMeta(&untouched)
+
+	_, err = client.WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.ResponseMeta{}, untouched, "Original client should not store metadata")
+}
+
+func Test_response_meta_of_failed_request(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.JSON(http.StatusNotFound, map[string]string{"error": "no such vps"}))
+
+	var meta lvlup.ResponseMeta
+	_, err := client.WithResponseMeta(&meta).VPS(7).State()
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, http.StatusNotFound, meta.StatusCode)
+	assert.Equal(t, `{"error":"no such vps"}`, string(meta.Body))
+}
+
+func Test_response_meta_latency_excludes_waits(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", quotaHandler(http.StatusOK, map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "0.2",
+	}, &requests))
+
+	_, err := client.WalletBalance()
+	assert.Nil(t, err, "Error should be nil")
+
+	var meta lvlup.ResponseMeta
+	start := time.Now()
+	_, err = client.WithResponseMeta(&meta).WalletBalance()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(150*time.Millisecond), "Request should wait for the reset")
+	assert.Less(t, int64(meta.Latency), int64(100*time.Millisecond), "Latency should not include the wait")
+}
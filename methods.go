@@ -0,0 +1,104 @@
+package lvlup
+
+import (
+	"sort"
+	"strconv"
+)
+
+// PaymentMethod represents method used to make a payment, as reported in methodId.
+//
+// The api documents neither names of method ids nor an endpoint listing them,
+// so they are named with PaymentMethodCatalog built by the caller, for example
+// after checking the ids used by the account in the LvlUp panel.
+type PaymentMethod int
+
+// MethodUnknown represents payment without a method id.
+const MethodUnknown PaymentMethod = 0
+
+// String returns id of the method.
+func (pm PaymentMethod) String() string {
+	return strconv.Itoa(int(pm))
+}
+
+// PaymentMethodCatalog represents names of payment methods, keyed by method id.
+// A nil catalog is empty.
+type PaymentMethodCatalog map[PaymentMethod]string
+
+// Name returns name of the method, or its id if the method is missing from the catalog.
+func (pmc PaymentMethodCatalog) Name(method PaymentMethod) string {
+	if name, ok := pmc[method]; ok {
+		return name
+	}
+
+	return method.String()
+}
+
+// Methods returns all methods in the catalog, sorted by id.
+func (pmc PaymentMethodCatalog) Methods() []PaymentMethod {
+	methods := make([]PaymentMethod, 0, len(pmc))
+	for method := range pmc {
+		methods = append(methods, method)
+	}
+
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+
+	return methods
+}
+
+// Method returns method used to make the payment.
+func (item ListPaymentsResultItem) Method() PaymentMethod {
+	return PaymentMethod(item.MethodId)
+}
+
+// GroupByMethod groups payments by method used to make them.
+func GroupByMethod(items []ListPaymentsResultItem) map[PaymentMethod][]ListPaymentsResultItem {
+	groups := map[PaymentMethod][]ListPaymentsResultItem{}
+
+	for _, item := range items {
+		groups[item.Method()] = append(groups[item.Method()], item)
+	}
+
+	return groups
+}
+
+// MethodStats represents statistics of payments made with a method.
+type MethodStats struct {
+	Method PaymentMethod
+	// Name is name of the method in the catalog, or its id.
+	Name        string
+	Count       int
+	TotalPlnInt int
+}
+
+// PaymentMethodStats allows to count payments and sum their amounts per method.
+// Methods are named with the catalog, which may be nil.
+// It returns stats sorted by method id and any errors encountered while parsing amounts.
+func PaymentMethodStats(items []ListPaymentsResultItem, catalog PaymentMethodCatalog) ([]MethodStats, error) {
+	stats := map[PaymentMethod]*MethodStats{}
+
+	for _, item := range items {
+		amount, err := parseAmount(item.Amount)
+
+		if err != nil {
+			return nil, err
+		}
+
+		method := item.Method()
+
+		if _, ok := stats[method]; !ok {
+			stats[method] = &MethodStats{Method: method, Name: catalog.Name(method)}
+		}
+
+		stats[method].Count++
+		stats[method].TotalPlnInt += amount
+	}
+
+	result := make([]MethodStats, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Method < result[j].Method })
+
+	return result, nil
+}
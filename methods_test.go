@@ -0,0 +1,52 @@
+package lvlup_test
+
+import (
+	"testing"
+
+	"github.com/senicko/lvlup"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	methodTransfer = lvlup.PaymentMethod(1)
+	methodBLIK     = lvlup.PaymentMethod(4)
+)
+
+func Test_payment_method_catalog(t *testing.T) {
+	catalog := lvlup.PaymentMethodCatalog{methodBLIK: "BLIK", methodTransfer: "Przelew"}
+
+	assert.Equal(t, "BLIK", catalog.Name(methodBLIK))
+	assert.Equal(t, "99", catalog.Name(lvlup.PaymentMethod(99)), "Missing methods should be named with their id")
+	assert.Equal(t, []lvlup.PaymentMethod{methodTransfer, methodBLIK}, catalog.Methods())
+
+	var empty lvlup.PaymentMethodCatalog
+	assert.Equal(t, "4", empty.Name(methodBLIK))
+	assert.Empty(t, empty.Methods())
+}
+
+func Test_payment_method_from_item(t *testing.T) {
+	item := lvlup.ListPaymentsResultItem{MethodId: 3}
+
+	assert.Equal(t, lvlup.PaymentMethod(3), item.Method())
+	assert.Equal(t, "3", item.Method().String())
+}
+
+func Test_group_by_method(t *testing.T) {
+	items := []lvlup.ListPaymentsResultItem{
+		{Id: 1, Amount: "1.00", MethodId: int(methodBLIK)},
+		{Id: 2, Amount: "2.50", MethodId: int(methodTransfer)},
+		{Id: 3, Amount: "3.00", MethodId: int(methodBLIK)},
+	}
+
+	groups := lvlup.GroupByMethod(items)
+	assert.Len(t, groups[methodBLIK], 2)
+	assert.Len(t, groups[methodTransfer], 1)
+
+	stats, err := lvlup.PaymentMethodStats(items, lvlup.PaymentMethodCatalog{methodBLIK: "BLIK"})
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []lvlup.MethodStats{
+		{Method: methodTransfer, Name: "1", Count: 1, TotalPlnInt: 250},
+		{Method: methodBLIK, Name: "BLIK", Count: 2, TotalPlnInt: 400},
+	}, stats)
+}
@@ -0,0 +1,79 @@
+package lvlup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts lists layouts in which the api returns dates.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTime parses date returned by the api.
+func parseTime(value string) (time.Time, error) {
+	var err error
+
+	for _, layout := range timeLayouts {
+		var parsed time.Time
+
+		if parsed, err = time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// parseAmount parses amount like "24.99" into grosz.
+func parseAmount(amount string) (int, error) {
+	value := strings.TrimSpace(amount)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction := value, ""
+	if i := strings.IndexAny(value, ".,"); i >= 0 {
+		whole, fraction = value[:i], value[i+1:]
+	}
+
+	if whole == "" || len(fraction) > 2 {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	for _, part := range []string{whole, fraction} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return 0, fmt.Errorf("invalid amount %q", amount)
+			}
+		}
+	}
+
+	grosz, err := strconv.Atoi(whole + fraction)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	if negative {
+		grosz = -grosz
+	}
+
+	return grosz, nil
+}
+
+// formatAmount formats grosz as amount like "24.99" using specified decimal separator.
+func formatAmount(grosz int, separator string) string {
+	sign := ""
+	if grosz < 0 {
+		sign = "-"
+		grosz = -grosz
+	}
+
+	return fmt.Sprintf("%s%d%s%02d", sign, grosz/100, separator, grosz%100)
+}
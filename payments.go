@@ -1,23 +1,25 @@
 package lvlup
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 // CreatePaymentOptions represents available options for CreatePayment func.
 type CreatePaymentOptions struct {
-	Amount      string `json:"amount"`
-	RedirectUrl string `json:"redirectUrl"`
-	WebhookUrl  string `json:"webhookUrl"`
+	Amount         string `json:"amount"`
+	RedirectUrl    string `json:"redirectUrl"`
+	WebhookUrl     string `json:"webhookUrl"`
+	IdempotencyKey string `json:"-"`
 }
 
 // CreatePaymentResult represents result of CreatePayment func.
 type CreatePaymentResult struct {
-	Id  string `json:"id"`
-	Url string `json:"url"`
+	Id  PaymentID `json:"id"`
+	Url string    `json:"url"`
 }
 
 // CreatePaymentOption represents a functional option for CreatePayment func.
@@ -37,6 +39,17 @@ func WithWebhook(url string) CreatePaymentOption {
 	}
 }
 
+// WithIdempotencyKey sets key identifying the payment across retries.
+// The key is sent in Idempotency-Key header and, if the client has an IdempotencyStore,
+// a result already created with the same key is returned without making a request.
+// If an earlier request with the key failed without telling whether the payment was
+// created, ErrOutcomeUnknown is returned instead of sending the request again.
+func WithIdempotencyKey(key string) CreatePaymentOption {
+	return func(cpo *CreatePaymentOptions) {
+		cpo.IdempotencyKey = key
+	}
+}
+
 // CreatePayment allows to create a new payment url.
 // It returns result of a request and any errors encountered.
 func (lc LvlClient) CreatePayment(amount string, opts ...CreatePaymentOption) (*CreatePaymentResult, error) {
@@ -50,46 +63,69 @@ func (lc LvlClient) CreatePayment(amount string, opts ...CreatePaymentOption) (*
 		opt(options)
 	}
 
-	payload, err := json.Marshal(options)
+	headers := map[string]string{}
+	store := lc.IdempotencyStore
 
-	if err != nil {
-		return nil, err
+	if options.IdempotencyKey == "" {
+		store = nil
+	} else {
+		headers["Idempotency-Key"] = options.IdempotencyKey
 	}
 
-	response, err := lc.post(
-		"/wallet/up",
-		withBody(payload),
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
+	if store != nil {
+		if err := lc.Err(); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
-	}
+		if result, ok := store.Get(options.IdempotencyKey); ok {
+			return result, nil
+		}
 
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
+		if !store.Reserve(options.IdempotencyKey) {
+			return nil, fmt.Errorf("%w: idempotency key %q", ErrOutcomeUnknown, options.IdempotencyKey)
+		}
 	}
 
 	var result CreatePaymentResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err := lc.call(
+		operation{Name: "CreatePayment", Method: http.MethodPost, Path: "/wallet/up"},
+		options, &result, withHeaders(headers),
+	); err != nil {
+		// Only a rejected request surely didn't create the payment,
+		// after other errors the key stays reserved until it expires.
+		var apiErr *APIError
+		if store != nil && errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+			store.Release(options.IdempotencyKey)
+		}
+
 		return nil, err
 	}
 
+	if store != nil {
+		store.Put(options.IdempotencyKey, &result)
+	}
+
 	return &result, nil
 }
 
 // ListPaymentsResultItem represents single item from ListPayments func.
 type ListPaymentsResultItem struct {
-	Amount      string `json:"amount"`
-	CreatedAt   string `json:"createdAt"`
-	Description string `json:"description"`
-	Id          int    `json:"id"`
-	MethodId    int    `json:"methodId"`
-	ServiceId   int    `json:"serviceId"`
+	Amount      string        `json:"amount"`
+	CreatedAt   string        `json:"createdAt"`
+	Description string        `json:"description"`
+	Id          PaymentItemID `json:"id"`
+	MethodId    int           `json:"methodId"`
+	ServiceId   ServiceID     `json:"serviceId"`
+}
+
+// CreatedAtTime allows to get the time at which the payment was created.
+func (item ListPaymentsResultItem) CreatedAtTime() (time.Time, error) {
+	return parseTime(item.CreatedAt)
+}
+
+// AmountPlnInt allows to get the payment amount in grosz.
+func (item ListPaymentsResultItem) AmountPlnInt() (int, error) {
+	return parseAmount(item.Amount)
 }
 
 // ListPaymentsResult represents result of ListPayments func.
@@ -112,16 +148,16 @@ func WithLimit(limit int) ListPaymentsOption {
 }
 
 // WithBeforeId allows to set payment id before which payments should be returned.
-func WithBeforeId(beforeId int) ListPaymentsOption {
+func WithBeforeId(beforeId PaymentItemID) ListPaymentsOption {
 	return func(lpo *ListPaymentsOptions) {
-		(*lpo)["beforeId"] = strconv.Itoa(beforeId)
+		(*lpo)["beforeId"] = beforeId.String()
 	}
 }
 
 // WithAfterId allows to set payment id after which payments should be returned.
-func WithAfterId(afterId int) ListPaymentsOption {
+func WithAfterId(afterId PaymentItemID) ListPaymentsOption {
 	return func(lpo *ListPaymentsOptions) {
-		(*lpo)["afterId"] = strconv.Itoa(afterId)
+		(*lpo)["afterId"] = afterId.String()
 	}
 }
 
@@ -134,26 +170,11 @@ func (lc LvlClient) ListPayments(opts ...ListPaymentsOption) (*ListPaymentsResul
 		opt(&options)
 	}
 
-	response, err := lc.get(
-		"/payments",
-		withQuery(options),
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
 	var result ListPaymentsResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err := lc.call(
+		operation{Name: "ListPayments", Method: http.MethodGet, Path: "/payments"},
+		nil, &result, withQuery(options),
+	); err != nil {
 		return nil, err
 	}
 
@@ -169,25 +190,11 @@ type WalletBalanceResult struct {
 // WalletBalance allows to get current wallet balance.
 // It returns request result and any errors encountered.
 func (lc LvlClient) WalletBalance() (*WalletBalanceResult, error) {
-	response, err := lc.get(
-		"/wallet",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
 	var result WalletBalanceResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err := lc.call(
+		operation{Name: "WalletBalance", Method: http.MethodGet, Path: "/wallet"},
+		nil, &result,
+	); err != nil {
 		return nil, err
 	}
 
@@ -197,38 +204,65 @@ func (lc LvlClient) WalletBalance() (*WalletBalanceResult, error) {
 // InspectPaymentResult represents result of InspectPayment func.
 type InspectPaymentResult struct {
 	AmountInt        int    `json:"amountInt"`
-	AmountStr        string `json:"amuntStr"`
+	AmountStr        string `json:"amountStr"`
 	AmountWithFeeInt int    `json:"amountWithFeeInt"`
 	AmountWithFeeStr string `json:"amountWithFeeStr"`
 	Payed            bool   `json:"payed"`
 }
 
 // InspectPayment allows to inspect a payment.
-// It returns request result and any errors encountered.
-func (lc LvlClient) InspectPayment(paymentId string) (*InspectPaymentResult, error) {
-	response, err := lc.get(
-		"/wallet/up/"+paymentId,
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode == http.StatusNotFound {
-		return nil, nil
-	} else if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
+// It returns request result and any errors encountered, ErrNotFound if the payment doesn't exist.
+func (lc LvlClient) InspectPayment(paymentId PaymentID) (*InspectPaymentResult, error) {
+	if paymentId == "" {
+		return nil, fmt.Errorf("%w: empty payment id", ErrInvalidID)
 	}
 
 	var result InspectPaymentResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err := lc.call(
+		operation{Name: "InspectPayment", Method: http.MethodGet, Path: buildPath("wallet", "up", paymentId.String())},
+		nil, &result,
+	); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
+
+// ErrStopIteration can be returned from EachPayment callback to stop walking payments without an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// EachPayment allows to walk through all payments, from the newest to the oldest.
+// Payments are fetched page by page using WithBeforeId, so only one page is kept in memory.
+// It returns any errors encountered, including ones returned by fn other than ErrStopIteration.
+func (lc LvlClient) EachPayment(pageSize int, fn func(ListPaymentsResultItem) error) error {
+	opts := []ListPaymentsOption{WithLimit(pageSize)}
+
+	for {
+		page, err := lc.ListPayments(opts...)
+
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page.Items {
+			if err := fn(item); err == ErrStopIteration {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if len(page.Items) < pageSize || len(page.Items) == 0 {
+			return nil
+		}
+
+		oldest := page.Items[0].Id
+		for _, item := range page.Items {
+			if item.Id < oldest {
+				oldest = item.Id
+			}
+		}
+
+		opts = []ListPaymentsOption{WithLimit(pageSize), WithBeforeId(oldest)}
+	}
+}
@@ -0,0 +1,328 @@
+package lvlup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// PaymentsExportFormat represents format in which payments are exported.
+type PaymentsExportFormat int
+
+const (
+	// ExportCSV writes comma separated values with a header row.
+	ExportCSV PaymentsExportFormat = iota
+	// ExportJSONLines writes one json object per line.
+	ExportJSONLines
+	// ExportAccounting writes semicolon separated values with Polish headers,
+	// decimal comma amounts and a totals row, as expected by Polish accounting software.
+	ExportAccounting
+)
+
+// PaymentsFilter represents criteria selecting payments.
+// Zero From or To leaves the corresponding side of the date range open,
+// and empty ServiceIds or MethodIds match every id.
+type PaymentsFilter struct {
+	From       time.Time
+	To         time.Time
+	ServiceIds []ServiceID
+	MethodIds  []int
+}
+
+// each walks through payments matching the filter, from the newest to the oldest.
+// fn receives parsed creation date of every payment.
+func (pf PaymentsFilter) each(lc LvlClient, pageSize int, fn func(ListPaymentsResultItem, time.Time) error) error {
+	return lc.EachPayment(pageSize, func(item ListPaymentsResultItem) error {
+		createdAt, err := parseTime(item.CreatedAt)
+
+		if err != nil {
+			return fmt.Errorf("payment %d: %w", item.Id, err)
+		}
+
+		if !pf.To.IsZero() && !createdAt.Before(pf.To) {
+			return nil
+		}
+
+		if !pf.From.IsZero() && createdAt.Before(pf.From) {
+			return ErrStopIteration
+		}
+
+		if !containsServiceId(pf.ServiceIds, item.ServiceId) || !containsId(pf.MethodIds, item.MethodId) {
+			return nil
+		}
+
+		return fn(item, createdAt)
+	})
+}
+
+// ExportPaymentsOptions represents available options for ExportPayments func.
+type ExportPaymentsOptions struct {
+	PaymentsFilter
+	Format   PaymentsExportFormat
+	Location *time.Location
+	PageSize int
+	Methods  PaymentMethodCatalog
+}
+
+// ExportPaymentsOption represents a functional option for ExportPayments func.
+type ExportPaymentsOption func(*ExportPaymentsOptions)
+
+// WithExportFormat sets format in which payments are written.
+func WithExportFormat(format PaymentsExportFormat) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.Format = format
+	}
+}
+
+// WithDateRange allows to export only payments created in [from, to) range.
+// Zero time leaves the corresponding side of the range open.
+func WithDateRange(from time.Time, to time.Time) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.From = from
+		epo.To = to
+	}
+}
+
+// WithServiceIds allows to export only payments for specified services.
+func WithServiceIds(serviceIds ...ServiceID) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.ServiceIds = serviceIds
+	}
+}
+
+// WithMethodIds allows to export only payments made with specified methods.
+func WithMethodIds(methodIds ...int) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.MethodIds = methodIds
+	}
+}
+
+// WithExportLocation sets time zone in which dates are written.
+func WithExportLocation(location *time.Location) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.Location = location
+	}
+}
+
+// WithPaymentMethodCatalog sets names of payment methods written in accounting format.
+// Methods missing from the catalog are written as their id.
+func WithPaymentMethodCatalog(catalog PaymentMethodCatalog) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.Methods = catalog
+	}
+}
+
+// WithExportPageSize sets how many payments are fetched per request.
+func WithExportPageSize(pageSize int) ExportPaymentsOption {
+	return func(epo *ExportPaymentsOptions) {
+		epo.PageSize = pageSize
+	}
+}
+
+// ExportPaymentsSummary represents result of ExportPayments func.
+type ExportPaymentsSummary struct {
+	Count       int
+	TotalPlnInt int
+}
+
+// ExportPayments allows to write payments matching options to w.
+// Payments are streamed from the newest to the oldest, page by page.
+// It returns summary of written payments and any errors encountered.
+func (lc LvlClient) ExportPayments(w io.Writer, opts ...ExportPaymentsOption) (*ExportPaymentsSummary, error) {
+	options := &ExportPaymentsOptions{
+		Format:   ExportCSV,
+		Location: time.UTC,
+		PageSize: 100,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var writer paymentsWriter
+
+	switch options.Format {
+	case ExportCSV:
+		writer = newCSVPaymentsWriter(w, ',', false, nil)
+	case ExportJSONLines:
+		writer = &jsonLinesPaymentsWriter{encoder: json.NewEncoder(w)}
+	case ExportAccounting:
+		writer = newCSVPaymentsWriter(w, ';', true, options.Methods)
+	default:
+		return nil, fmt.Errorf("unknown export format %d", options.Format)
+	}
+
+	if err := writer.begin(); err != nil {
+		return nil, err
+	}
+
+	summary := &ExportPaymentsSummary{}
+
+	err := options.PaymentsFilter.each(lc, options.PageSize, func(item ListPaymentsResultItem, createdAt time.Time) error {
+		amount, err := parseAmount(item.Amount)
+
+		if err != nil {
+			return fmt.Errorf("payment %d: %w", item.Id, err)
+		}
+
+		summary.Count++
+		summary.TotalPlnInt += amount
+
+		return writer.write(item, createdAt.In(options.Location), amount)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writer.end(summary); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// containsId reports whether id is in ids. Empty ids match every id.
+func containsId(ids []int, id int) bool {
+	if len(ids) == 0 {
+		return true
+	}
+
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsServiceId reports whether id is in ids. Empty ids match every id.
+func containsServiceId(ids []ServiceID, id ServiceID) bool {
+	if len(ids) == 0 {
+		return true
+	}
+
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paymentsWriter describes a writer of a single export format.
+type paymentsWriter interface {
+	begin() error
+	write(item ListPaymentsResultItem, createdAt time.Time, amount int) error
+	end(summary *ExportPaymentsSummary) error
+}
+
+// csvPaymentsWriter writes payments as csv.
+type csvPaymentsWriter struct {
+	writer     *csv.Writer
+	accounting bool
+	methods    PaymentMethodCatalog
+	row        int
+}
+
+// newCSVPaymentsWriter creates new csv writer.
+// In accounting mode headers are Polish, amounts use decimal comma, methods are named
+// with the catalog and a totals row is written.
+func newCSVPaymentsWriter(w io.Writer, comma rune, accounting bool, methods PaymentMethodCatalog) *csvPaymentsWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	return &csvPaymentsWriter{
+		writer:     writer,
+		accounting: accounting,
+		methods:    methods,
+	}
+}
+
+func (cw *csvPaymentsWriter) begin() error {
+	if cw.accounting {
+		return cw.writer.Write([]string{"Lp.", "Data", "Nr płatności", "Opis", "Usługa", "Metoda", "Kwota (PLN)"})
+	}
+
+	return cw.writer.Write([]string{"id", "createdAt", "description", "serviceId", "methodId", "amount"})
+}
+
+func (cw *csvPaymentsWriter) write(item ListPaymentsResultItem, createdAt time.Time, amount int) error {
+	cw.row++
+
+	if cw.accounting {
+		return cw.writer.Write([]string{
+			strconv.Itoa(cw.row),
+			createdAt.Format("2006-01-02"),
+			item.Id.String(),
+			item.Description,
+			item.ServiceId.String(),
+			cw.methods.Name(item.Method()),
+			formatAmount(amount, ","),
+		})
+	}
+
+	return cw.writer.Write([]string{
+		item.Id.String(),
+		createdAt.Format(time.RFC3339),
+		item.Description,
+		item.ServiceId.String(),
+		strconv.Itoa(item.MethodId),
+		formatAmount(amount, "."),
+	})
+}
+
+func (cw *csvPaymentsWriter) end(summary *ExportPaymentsSummary) error {
+	if cw.accounting {
+		// Number of payments is already in the last Lp, so only the total amount is summed up.
+		total := []string{"", "", "", "Razem", "", "", formatAmount(summary.TotalPlnInt, ",")}
+
+		if err := cw.writer.Write(total); err != nil {
+			return err
+		}
+	}
+
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// jsonLinesPaymentsWriter writes payments as json lines.
+type jsonLinesPaymentsWriter struct {
+	encoder *json.Encoder
+}
+
+// jsonLinesPayment represents a single line of json lines export.
+type jsonLinesPayment struct {
+	Id          PaymentItemID `json:"id"`
+	CreatedAt   string        `json:"createdAt"`
+	Description string        `json:"description"`
+	ServiceId   ServiceID     `json:"serviceId"`
+	MethodId    int           `json:"methodId"`
+	Amount      string        `json:"amount"`
+	AmountInt   int           `json:"amountInt"`
+}
+
+func (jw *jsonLinesPaymentsWriter) begin() error {
+	return nil
+}
+
+func (jw *jsonLinesPaymentsWriter) write(item ListPaymentsResultItem, createdAt time.Time, amount int) error {
+	return jw.encoder.Encode(jsonLinesPayment{
+		Id:          item.Id,
+		CreatedAt:   createdAt.Format(time.RFC3339),
+		Description: item.Description,
+		ServiceId:   item.ServiceId,
+		MethodId:    item.MethodId,
+		Amount:      formatAmount(amount, "."),
+		AmountInt:   amount,
+	})
+}
+
+func (jw *jsonLinesPaymentsWriter) end(summary *ExportPaymentsSummary) error {
+	return nil
+}
@@ -0,0 +1,141 @@
+package lvlup_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exportPayments() []lvlup.ListPaymentsResultItem {
+	return []lvlup.ListPaymentsResultItem{
+		{Id: 1, Amount: "5.00", CreatedAt: "2021-05-30T10:00:00Z", ServiceId: 1, MethodId: 1},
+		{Id: 2, Amount: "10.50", CreatedAt: "2021-06-01T10:00:00Z", ServiceId: 1, MethodId: 1, Description: "VPS"},
+		{Id: 3, Amount: "20.00", CreatedAt: "2021-06-15T10:00:00Z", ServiceId: 2, MethodId: 2},
+		{Id: 4, Amount: "1.99", CreatedAt: "2021-06-20T10:00:00Z", ServiceId: 1, MethodId: 2},
+		{Id: 5, Amount: "7.00", CreatedAt: "2021-07-01T10:00:00Z", ServiceId: 1, MethodId: 1},
+	}
+}
+
+func june() lvlup.ExportPaymentsOption {
+	return lvlup.WithDateRange(
+		time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+	)
+}
+
+func Test_each_payment_pages(t *testing.T) {
+	var beforeIds []string
+
+	pager := testutil.PaymentsPager(exportPayments())
+	handler := func(r *http.Request) (*http.Response, error) {
+		beforeIds = append(beforeIds, r.URL.Query().Get("beforeId"))
+		return pager(r)
+	}
+
+	client := testutil.NewTestLvlClient("token", handler)
+
+	var ids []lvlup.PaymentItemID
+	err := client.EachPayment(2, func(item lvlup.ListPaymentsResultItem) error {
+		ids = append(ids, item.Id)
+		return nil
+	})
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []lvlup.PaymentItemID{5, 4, 3, 2, 1}, ids)
+	assert.Equal(t, []string{"", "4", "2"}, beforeIds)
+}
+
+func Test_export_payments_csv(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(exportPayments()))
+
+	var out bytes.Buffer
+	summary, err := client.ExportPayments(&out, june(), lvlup.WithExportPageSize(2))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 3, summary.Count)
+	assert.Equal(t, 3249, summary.TotalPlnInt)
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, rows, 4)
+	assert.Equal(t, []string{"2", "2021-06-01T10:00:00Z", "VPS", "1", "1", "10.50"}, rows[3])
+}
+
+func Test_export_payments_filters(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(exportPayments()))
+
+	var out bytes.Buffer
+	summary, err := client.ExportPayments(&out, june(), lvlup.WithServiceIds(1), lvlup.WithMethodIds(2))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1, summary.Count)
+	assert.Equal(t, 199, summary.TotalPlnInt)
+}
+
+func Test_export_payments_json_lines(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(exportPayments()))
+
+	var out bytes.Buffer
+	_, err := client.ExportPayments(&out, june(), lvlup.WithExportFormat(lvlup.ExportJSONLines))
+	assert.Nil(t, err, "Error should be nil")
+
+	var ids []int
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line struct {
+			Id        int `json:"id"`
+			AmountInt int `json:"amountInt"`
+		}
+
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &line))
+		ids = append(ids, line.Id)
+	}
+
+	assert.Equal(t, []int{4, 3, 2}, ids)
+}
+
+func Test_export_payments_accounting(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(exportPayments()))
+
+	var out bytes.Buffer
+	_, err := client.ExportPayments(&out, june(), lvlup.WithExportFormat(lvlup.ExportAccounting))
+	assert.Nil(t, err, "Error should be nil")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 5)
+	assert.True(t, strings.HasPrefix(lines[0], "Lp.;Data;"))
+	assert.Equal(t, "3;2021-06-01;2;VPS;1;1;10,50", lines[3])
+	assert.Equal(t, ";;;Razem;;;32,49", lines[4])
+}
+
+func Test_export_payments_accounting_method_names(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(exportPayments()))
+
+	var out bytes.Buffer
+	_, err := client.ExportPayments(&out, june(),
+		lvlup.WithExportFormat(lvlup.ExportAccounting),
+		lvlup.WithPaymentMethodCatalog(lvlup.PaymentMethodCatalog{1: "Przelew"}),
+	)
+	assert.Nil(t, err, "Error should be nil")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "3;2021-06-01;2;VPS;1;Przelew;10,50", lines[3])
+}
+
+func Test_export_payments_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+
+	_, err := client.ExportPayments(&bytes.Buffer{})
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
@@ -0,0 +1,245 @@
+package lvlup
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CursorStore describes storage of the highest payment id seen by PaymentsSyncer.
+type CursorStore interface {
+	Load() (PaymentItemID, error)
+	Save(cursor PaymentItemID) error
+}
+
+// MemoryCursorStore represents CursorStore keeping the cursor in memory.
+// The zero value is ready to use.
+type MemoryCursorStore struct {
+	mu     sync.Mutex
+	cursor PaymentItemID
+}
+
+// Load returns the stored cursor.
+func (mcs *MemoryCursorStore) Load() (PaymentItemID, error) {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	return mcs.cursor, nil
+}
+
+// Save stores the cursor.
+func (mcs *MemoryCursorStore) Save(cursor PaymentItemID) error {
+	mcs.mu.Lock()
+	defer mcs.mu.Unlock()
+
+	mcs.cursor = cursor
+	return nil
+}
+
+// FileCursorStore represents CursorStore keeping the cursor in a file.
+// The file is replaced atomically, so a crash never leaves a partially written cursor.
+type FileCursorStore struct {
+	path string
+}
+
+// NewFileCursorStore creates new store keeping the cursor in file at path.
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{
+		path: path,
+	}
+}
+
+// Load returns the stored cursor, or 0 if the file does not exist yet.
+func (fcs *FileCursorStore) Load() (PaymentItemID, error) {
+	content, err := ioutil.ReadFile(fcs.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	cursor, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	return PaymentItemID(cursor), err
+}
+
+// Save stores the cursor.
+func (fcs *FileCursorStore) Save(cursor PaymentItemID) error {
+	return writeFileAtomic(fcs.path, []byte(cursor.String()))
+}
+
+// writeFileAtomic replaces content of the file with data.
+// The data is written to a temporary file with 0600 permissions, which is then renamed.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// PaymentsSink describes receiver of payments delivered by PaymentsSyncer.
+type PaymentsSink interface {
+	Deliver(item ListPaymentsResultItem) error
+}
+
+// PaymentsSinkFunc allows to use a func as PaymentsSink.
+type PaymentsSinkFunc func(item ListPaymentsResultItem) error
+
+// Deliver calls f(item).
+func (f PaymentsSinkFunc) Deliver(item ListPaymentsResultItem) error {
+	return f(item)
+}
+
+// PaymentsSyncerOptions represents available options for PaymentsSyncer.
+type PaymentsSyncerOptions struct {
+	PageSize int
+}
+
+// PaymentsSyncerOption represents a functional option for PaymentsSyncer.
+type PaymentsSyncerOption func(*PaymentsSyncerOptions)
+
+// WithSyncPageSize sets how many payments are fetched per request.
+func WithSyncPageSize(pageSize int) PaymentsSyncerOption {
+	return func(pso *PaymentsSyncerOptions) {
+		pso.PageSize = pageSize
+	}
+}
+
+// PaymentsSyncer delivers payments newer than the stored cursor to a sink.
+type PaymentsSyncer struct {
+	client  *LvlClient
+	store   CursorStore
+	sink    PaymentsSink
+	options *PaymentsSyncerOptions
+	mu      sync.Mutex
+}
+
+// NewPaymentsSyncer creates new syncer delivering payments to the sink.
+func NewPaymentsSyncer(client *LvlClient, store CursorStore, sink PaymentsSink, opts ...PaymentsSyncerOption) *PaymentsSyncer {
+	options := &PaymentsSyncerOptions{
+		PageSize: 100,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &PaymentsSyncer{
+		client:  client,
+		store:   store,
+		sink:    sink,
+		options: options,
+	}
+}
+
+// Sync delivers all payments newer than the stored cursor, in ascending id order.
+// Payments are delivered without gaps whether the api lists pages after the cursor
+// from the oldest or from the newest payment.
+// The cursor is saved after every delivered payment, so a failed or interrupted sync
+// resumes from the first undelivered payment. A crash between delivery and saving
+// the cursor redelivers that single payment, so sinks should upsert by payment id.
+// It returns number of delivered payments and any errors encountered.
+func (ps *PaymentsSyncer) Sync() (int, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	cursor, err := ps.store.Load()
+
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+
+	for {
+		page, err := ps.client.ListPayments(WithAfterId(cursor), WithLimit(ps.options.PageSize))
+
+		if err != nil {
+			return delivered, err
+		}
+
+		items, err := ps.oldestAfter(cursor, page.Items)
+
+		if err != nil {
+			return delivered, err
+		}
+
+		sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+
+		progressed := false
+
+		for _, item := range items {
+			if item.Id <= cursor {
+				continue
+			}
+
+			if err := ps.sink.Deliver(item); err != nil {
+				return delivered, err
+			}
+
+			if err := ps.store.Save(item.Id); err != nil {
+				return delivered, err
+			}
+
+			cursor = item.Id
+			delivered++
+			progressed = true
+		}
+
+		if !progressed || len(page.Items) < ps.options.PageSize {
+			return delivered, nil
+		}
+	}
+}
+
+// oldestAfter returns payments directly following the cursor, starting from the page listed after it.
+// A full page may hold the newest payments, so older ones are listed with WithBeforeId
+// until there is nothing left between the cursor and the oldest listed payment.
+func (ps *PaymentsSyncer) oldestAfter(cursor PaymentItemID, items []ListPaymentsResultItem) ([]ListPaymentsResultItem, error) {
+	for len(items) >= ps.options.PageSize && len(items) > 0 {
+		oldest := items[0].Id
+		for _, item := range items {
+			if item.Id < oldest {
+				oldest = item.Id
+			}
+		}
+
+		gap, err := ps.client.ListPayments(WithAfterId(cursor), WithBeforeId(oldest), WithLimit(ps.options.PageSize))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(gap.Items) == 0 {
+			break
+		}
+
+		items = gap.Items
+	}
+
+	return items, nil
+}
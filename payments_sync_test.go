@@ -0,0 +1,118 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func syncPayments(ids ...lvlup.PaymentItemID) []lvlup.ListPaymentsResultItem {
+	items := make([]lvlup.ListPaymentsResultItem, 0, len(ids))
+
+	for _, id := range ids {
+		items = append(items, lvlup.ListPaymentsResultItem{Id: id})
+	}
+
+	return items
+}
+
+func Test_payments_syncer_delivers_new_payments(t *testing.T) {
+	items := syncPayments(1, 2, 3, 4, 5)
+	client := testutil.NewTestLvlClient("token", func(r *http.Request) (*http.Response, error) {
+		return testutil.PaymentsPager(items)(r)
+	})
+
+	store := &lvlup.MemoryCursorStore{}
+	assert.Nil(t, store.Save(2))
+
+	var delivered []lvlup.PaymentItemID
+	syncer := lvlup.NewPaymentsSyncer(client, store, lvlup.PaymentsSinkFunc(func(item lvlup.ListPaymentsResultItem) error {
+		delivered = append(delivered, item.Id)
+		return nil
+	}), lvlup.WithSyncPageSize(2))
+
+	count, err := syncer.Sync()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []lvlup.PaymentItemID{3, 4, 5}, delivered)
+
+	items = append(items, syncPayments(6)...)
+
+	count, err = syncer.Sync()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []lvlup.PaymentItemID{3, 4, 5, 6}, delivered)
+
+	cursor, _ := store.Load()
+	assert.Equal(t, lvlup.PaymentItemID(6), cursor)
+}
+
+func Test_payments_syncer_newest_first_pages(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.NewestFirstPaymentsPager(syncPayments(1, 2, 3, 4, 5, 6, 7)))
+	store := &lvlup.MemoryCursorStore{}
+	assert.Nil(t, store.Save(1))
+
+	var delivered []lvlup.PaymentItemID
+	syncer := lvlup.NewPaymentsSyncer(client, store, lvlup.PaymentsSinkFunc(func(item lvlup.ListPaymentsResultItem) error {
+		delivered = append(delivered, item.Id)
+		return nil
+	}), lvlup.WithSyncPageSize(2))
+
+	count, err := syncer.Sync()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 6, count)
+	assert.Equal(t, []lvlup.PaymentItemID{2, 3, 4, 5, 6, 7}, delivered, "Payments should be delivered without gaps")
+}
+
+func Test_payments_syncer_resumes_after_failure(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(syncPayments(1, 2, 3, 4)))
+	store := lvlup.NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+
+	var delivered []lvlup.PaymentItemID
+	failOn := lvlup.PaymentItemID(3)
+
+	sink := lvlup.PaymentsSinkFunc(func(item lvlup.ListPaymentsResultItem) error {
+		if item.Id == failOn {
+			return errors.New("sink unavailable")
+		}
+
+		delivered = append(delivered, item.Id)
+		return nil
+	})
+
+	_, err := lvlup.NewPaymentsSyncer(client, store, sink).Sync()
+	assert.NotNil(t, err, "Error should not be nil")
+
+	cursor, err := store.Load()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentItemID(2), cursor)
+
+	failOn = 0
+	_, err = lvlup.NewPaymentsSyncer(client, store, sink).Sync()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []lvlup.PaymentItemID{1, 2, 3, 4}, delivered)
+}
+
+func Test_file_cursor_store_missing_file(t *testing.T) {
+	store := lvlup.NewFileCursorStore(filepath.Join(t.TempDir(), "cursor"))
+
+	cursor, err := store.Load()
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, lvlup.PaymentItemID(0), cursor)
+}
+
+func Test_payments_syncer_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	sink := lvlup.PaymentsSinkFunc(func(lvlup.ListPaymentsResultItem) error { return nil })
+
+	_, err := lvlup.NewPaymentsSyncer(client, &lvlup.MemoryCursorStore{}, sink).Sync()
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
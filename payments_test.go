@@ -3,6 +3,7 @@ package lvlup_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -156,7 +157,7 @@ func Test_set_limit_for_list_payments(t *testing.T) {
 }
 
 func Test_set_beforeId_for_list_payments(t *testing.T) {
-	testBeforeId := 10
+	testBeforeId := lvlup.PaymentItemID(10)
 	expectedBeforeId := "10"
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -185,7 +186,7 @@ func Test_set_beforeId_for_list_payments(t *testing.T) {
 }
 
 func Test_set_afterId_for_list_payments(t *testing.T) {
-	testAfterId := 10
+	testAfterId := lvlup.PaymentItemID(10)
 	expectedAfterId := "10"
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -295,8 +296,8 @@ func Test_get_wallet_balance_server_error(t *testing.T) {
 
 func Test_inspect_payment(t *testing.T) {
 	apiKey := "token"
-	paymentId := "1"
-	expectedPath := "/v4/wallet/up/" + paymentId
+	paymentId := lvlup.PaymentID("1")
+	expectedPath := "/v4/wallet/up/" + paymentId.String()
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -337,8 +338,8 @@ func Test_inspect_payment_server_error(t *testing.T) {
 func Test_inspect_payment_not_found_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusNotFound))
 
-	result, err := client.InspectPayment("")
+	result, err := client.InspectPayment("id")
 
-	assert.Nil(t, err, "Error should be nil")
+	assert.True(t, errors.Is(err, lvlup.ErrNotFound), "Error should be ErrNotFound")
 	assert.Nil(t, result, "Result should be nil")
 }
@@ -0,0 +1,360 @@
+package lvlup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrProxmoNotCached is returned by ProxmoStore when there are no credentials of the VPS.
+var ErrProxmoNotCached = errors.New("proxmo credentials not cached")
+
+// ErrMissingRequester is returned by ProxmoBroker when credentials are requested anonymously.
+var ErrMissingRequester = errors.New("requester is required")
+
+// ProxmoStoreError is returned together with newly issued credentials which could not be stored.
+// The password is already reset by then, so the returned credentials are the only working ones.
+type ProxmoStoreError struct {
+	VPSId VPSID
+	Err   error
+}
+
+func (e *ProxmoStoreError) Error() string {
+	return fmt.Sprintf("proxmo credentials of vps %s issued but not stored: %v", e.VPSId, e.Err)
+}
+
+func (e *ProxmoStoreError) Unwrap() error {
+	return e.Err
+}
+
+// ProxmoStore describes storage of proxmo credentials of VPSes.
+type ProxmoStore interface {
+	Load(vpsId VPSID) (*ProxmoUser, error)
+	Save(vpsId VPSID, user *ProxmoUser) error
+}
+
+// proxmoRecord represents stored proxmo credentials.
+// Password is kept as plain string, because Secret is redacted when encoded.
+type proxmoRecord struct {
+	Password  string    `json:"password"`
+	Url       string    `json:"url"`
+	Username  string    `json:"username"`
+	RotatedAt time.Time `json:"rotatedAt"`
+}
+
+// EncryptedProxmoStore represents ProxmoStore keeping credentials in a file encrypted with AES-GCM.
+type EncryptedProxmoStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewEncryptedProxmoStore creates new store keeping credentials in file at path.
+// The key has to be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func NewEncryptedProxmoStore(path string, key []byte) (*EncryptedProxmoStore, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedProxmoStore{
+		path: path,
+		aead: aead,
+	}, nil
+}
+
+// Load returns cached credentials of the VPS.
+// It returns ErrProxmoNotCached if there are none.
+func (eps *EncryptedProxmoStore) Load(vpsId VPSID) (*ProxmoUser, error) {
+	eps.mu.Lock()
+	defer eps.mu.Unlock()
+
+	records, err := eps.read()
+
+	if err != nil {
+		return nil, err
+	}
+
+	record, ok := records[vpsId.String()]
+
+	if !ok {
+		return nil, ErrProxmoNotCached
+	}
+
+	return &ProxmoUser{
+		Password: Secret(record.Password),
+		Url:      record.Url,
+		Username: record.Username,
+	}, nil
+}
+
+// Save stores credentials of the VPS.
+func (eps *EncryptedProxmoStore) Save(vpsId VPSID, user *ProxmoUser) error {
+	eps.mu.Lock()
+	defer eps.mu.Unlock()
+
+	records, err := eps.read()
+
+	if err != nil {
+		return err
+	}
+
+	records[vpsId.String()] = proxmoRecord{
+		Password:  user.Password.Reveal(),
+		Url:       user.Url,
+		Username:  user.Username,
+		RotatedAt: time.Now().UTC(),
+	}
+
+	plaintext, err := json.Marshal(records)
+
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, eps.aead.NonceSize())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(eps.path, eps.aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+// read decrypts all stored records.
+func (eps *EncryptedProxmoStore) read() (map[string]proxmoRecord, error) {
+	records := map[string]proxmoRecord{}
+	ciphertext, err := ioutil.ReadFile(eps.path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	nonceSize := eps.aead.NonceSize()
+
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("proxmo store %s is corrupted", eps.path)
+	}
+
+	plaintext, err := eps.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("proxmo store %s can't be decrypted: %w", eps.path, err)
+	}
+
+	if err := json.Unmarshal(plaintext, &records); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// ProxmoAction represents what the broker did to serve a request.
+type ProxmoAction string
+
+const (
+	ProxmoCached  ProxmoAction = "cached"
+	ProxmoIssued  ProxmoAction = "issued"
+	ProxmoRotated ProxmoAction = "rotated"
+)
+
+// ProxmoAuditEntry represents a single request for proxmo credentials.
+type ProxmoAuditEntry struct {
+	Time      time.Time    `json:"time"`
+	VPSId     VPSID        `json:"vpsId"`
+	Requester string       `json:"requester"`
+	Action    ProxmoAction `json:"action"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ProxmoAuditor describes receiver of audit entries emitted by ProxmoBroker.
+type ProxmoAuditor interface {
+	Audit(entry ProxmoAuditEntry)
+}
+
+// ProxmoAuditFunc represents ProxmoAuditor calling the func.
+type ProxmoAuditFunc func(entry ProxmoAuditEntry)
+
+// Audit calls the func.
+func (paf ProxmoAuditFunc) Audit(entry ProxmoAuditEntry) {
+	paf(entry)
+}
+
+// JSONLinesAuditLog represents ProxmoAuditor writing entries as json lines.
+type JSONLinesAuditLog struct {
+	mu      sync.Mutex
+	w       io.Writer
+	onError func(entry ProxmoAuditEntry, err error)
+}
+
+// JSONLinesAuditLogOption represents a functional option for JSONLinesAuditLog.
+type JSONLinesAuditLogOption func(*JSONLinesAuditLog)
+
+// WithAuditErrorHandler sets func called when an entry can't be written.
+func WithAuditErrorHandler(handler func(entry ProxmoAuditEntry, err error)) JSONLinesAuditLogOption {
+	return func(jal *JSONLinesAuditLog) {
+		jal.onError = handler
+	}
+}
+
+// NewJSONLinesAuditLog creates new audit log writing to w.
+func NewJSONLinesAuditLog(w io.Writer, opts ...JSONLinesAuditLogOption) *JSONLinesAuditLog {
+	jal := &JSONLinesAuditLog{
+		w: w,
+	}
+
+	for _, opt := range opts {
+		opt(jal)
+	}
+
+	return jal
+}
+
+// Audit writes the entry. Write errors are passed to the handler set with WithAuditErrorHandler.
+func (jal *JSONLinesAuditLog) Audit(entry ProxmoAuditEntry) {
+	jal.mu.Lock()
+	defer jal.mu.Unlock()
+
+	if err := json.NewEncoder(jal.w).Encode(entry); err != nil && jal.onError != nil {
+		jal.onError(entry, err)
+	}
+}
+
+// ProxmoBrokerOptions represents available options for ProxmoBroker.
+type ProxmoBrokerOptions struct {
+	Auditor ProxmoAuditor
+}
+
+// ProxmoBrokerOption represents a functional option for ProxmoBroker.
+type ProxmoBrokerOption func(*ProxmoBrokerOptions)
+
+// WithProxmoAuditor sets receiver of audit entries.
+func WithProxmoAuditor(auditor ProxmoAuditor) ProxmoBrokerOption {
+	return func(pbo *ProxmoBrokerOptions) {
+		pbo.Auditor = auditor
+	}
+}
+
+// ProxmoBroker hands out proxmo credentials without resetting the password on every request.
+// Credentials returned by GetProxmoUser are cached in the store until rotated.
+type ProxmoBroker struct {
+	client  *LvlClient
+	store   ProxmoStore
+	options *ProxmoBrokerOptions
+
+	mu sync.Mutex
+}
+
+// NewProxmoBroker creates new broker caching credentials in the store.
+func NewProxmoBroker(client *LvlClient, store ProxmoStore, opts ...ProxmoBrokerOption) *ProxmoBroker {
+	options := &ProxmoBrokerOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &ProxmoBroker{
+		client:  client,
+		store:   store,
+		options: options,
+	}
+}
+
+// Credentials allows to get proxmo credentials of the VPS on behalf of the requester.
+// Cached credentials are returned if present, otherwise new ones are issued with GetProxmoUser.
+// It returns the credentials and any errors encountered. If new credentials can't be
+// stored, they are returned together with *ProxmoStoreError.
+func (pb *ProxmoBroker) Credentials(vpsId VPSID, requester string) (*ProxmoUser, error) {
+	if requester == "" {
+		return nil, ErrMissingRequester
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	user, err := pb.store.Load(vpsId)
+
+	switch {
+	case err == nil:
+		pb.audit(vpsId, requester, ProxmoCached, nil)
+		return user, nil
+	case !errors.Is(err, ErrProxmoNotCached):
+		pb.audit(vpsId, requester, ProxmoCached, err)
+		return nil, err
+	}
+
+	return pb.issue(vpsId, requester, ProxmoIssued)
+}
+
+// Rotate allows to reset proxmo password of the VPS on behalf of the requester.
+// Previously handed out credentials stop working.
+// It returns the new credentials and any errors encountered. If new credentials can't be
+// stored, they are returned together with *ProxmoStoreError.
+func (pb *ProxmoBroker) Rotate(vpsId VPSID, requester string) (*ProxmoUser, error) {
+	if requester == "" {
+		return nil, ErrMissingRequester
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	return pb.issue(vpsId, requester, ProxmoRotated)
+}
+
+// issue resets the password and stores new credentials.
+// The credentials are returned even if they can't be stored, since the old ones stop working.
+func (pb *ProxmoBroker) issue(vpsId VPSID, requester string, action ProxmoAction) (*ProxmoUser, error) {
+	user, err := pb.client.GetProxmoUser(vpsId)
+
+	if err != nil {
+		pb.audit(vpsId, requester, action, err)
+		return nil, err
+	}
+
+	if err := pb.store.Save(vpsId, user); err != nil {
+		storeErr := &ProxmoStoreError{VPSId: vpsId, Err: err}
+		pb.audit(vpsId, requester, action, storeErr)
+		return user, storeErr
+	}
+
+	pb.audit(vpsId, requester, action, nil)
+
+	return user, nil
+}
+
+// audit emits audit entry if auditor is set.
+func (pb *ProxmoBroker) audit(vpsId VPSID, requester string, action ProxmoAction, err error) {
+	if pb.options.Auditor == nil {
+		return
+	}
+
+	entry := ProxmoAuditEntry{
+		Time:      time.Now().UTC(),
+		VPSId:     vpsId,
+		Requester: requester,
+		Action:    action,
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	pb.options.Auditor.Audit(entry)
+}
@@ -0,0 +1,182 @@
+package lvlup_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var proxmoKey = []byte("0123456789abcdef0123456789abcdef")
+
+// proxmoHandler resets the password on every request, like the api does.
+func proxmoHandler(resets *int) testutil.RoundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		*resets++
+
+		return testutil.JSON(http.StatusOK, json.RawMessage(fmt.Sprintf(
+			`{"password":"password-%d","url":"https://proxmo.example","username":"user"}`, *resets,
+		)))(r)
+	}
+}
+
+func Test_proxmo_broker_caches_credentials(t *testing.T) {
+	resets := 0
+	client := testutil.NewTestLvlClient("token", proxmoHandler(&resets))
+
+	path := filepath.Join(t.TempDir(), "proxmo.bin")
+	store, err := lvlup.NewEncryptedProxmoStore(path, proxmoKey)
+	assert.Nil(t, err, "Error should be nil")
+
+	var audit []lvlup.ProxmoAuditEntry
+	broker := lvlup.NewProxmoBroker(client, store, lvlup.WithProxmoAuditor(lvlup.ProxmoAuditFunc(func(entry lvlup.ProxmoAuditEntry) {
+		audit = append(audit, entry)
+	})))
+
+	first, err := broker.Credentials(7, "alice")
+	assert.Nil(t, err, "Error should be nil")
+
+	second, err := broker.Credentials(7, "bob")
+	assert.Nil(t, err, "Error should be nil")
+
+	assert.Equal(t, 1, resets)
+	assert.Equal(t, "password-1", first.Password.Reveal())
+	assert.Equal(t, first, second)
+
+	rotated, err := broker.Rotate(7, "alice")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "password-2", rotated.Password.Reveal())
+
+	third, err := broker.Credentials(7, "bob")
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "password-2", third.Password.Reveal())
+
+	assert.Len(t, audit, 4)
+	assert.Equal(t, lvlup.ProxmoIssued, audit[0].Action)
+	assert.Equal(t, "alice", audit[0].Requester)
+	assert.Equal(t, lvlup.ProxmoCached, audit[1].Action)
+	assert.Equal(t, "bob", audit[1].Requester)
+	assert.Equal(t, lvlup.ProxmoRotated, audit[2].Action)
+	assert.Equal(t, lvlup.VPSID(7), audit[2].VPSId)
+	assert.Equal(t, lvlup.ProxmoCached, audit[3].Action)
+}
+
+func Test_proxmo_broker_requires_requester(t *testing.T) {
+	resets := 0
+	client := testutil.NewTestLvlClient("token", proxmoHandler(&resets))
+	store, _ := lvlup.NewEncryptedProxmoStore(filepath.Join(t.TempDir(), "proxmo.bin"), proxmoKey)
+
+	_, err := lvlup.NewProxmoBroker(client, store).Credentials(7, "")
+
+	assert.True(t, errors.Is(err, lvlup.ErrMissingRequester), "Error should be ErrMissingRequester")
+	assert.Equal(t, 0, resets)
+}
+
+// failingProxmoStore represents store which can't save anything.
+type failingProxmoStore struct{}
+
+func (failingProxmoStore) Load(vpsId lvlup.VPSID) (*lvlup.ProxmoUser, error) {
+	return nil, lvlup.ErrProxmoNotCached
+}
+
+func (failingProxmoStore) Save(vpsId lvlup.VPSID, user *lvlup.ProxmoUser) error {
+	return errors.New("disk full")
+}
+
+func Test_proxmo_broker_returns_credentials_not_stored(t *testing.T) {
+	resets := 0
+	client := testutil.NewTestLvlClient("token", proxmoHandler(&resets))
+
+	var audit []lvlup.ProxmoAuditEntry
+	broker := lvlup.NewProxmoBroker(client, failingProxmoStore{}, lvlup.WithProxmoAuditor(lvlup.ProxmoAuditFunc(func(entry lvlup.ProxmoAuditEntry) {
+		audit = append(audit, entry)
+	})))
+
+	user, err := broker.Rotate(7, "alice")
+
+	var storeErr *lvlup.ProxmoStoreError
+	assert.True(t, errors.As(err, &storeErr), "Error should be ProxmoStoreError")
+	assert.Equal(t, lvlup.VPSID(7), storeErr.VPSId)
+	assert.NotNil(t, user, "Credentials should be returned")
+	assert.Equal(t, "password-1", user.Password.Reveal())
+	assert.Len(t, audit, 1)
+	assert.Contains(t, audit[0].Error, "disk full")
+}
+
+func Test_encrypted_proxmo_store(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxmo.bin")
+
+	store, err := lvlup.NewEncryptedProxmoStore(path, proxmoKey)
+	assert.Nil(t, err, "Error should be nil")
+
+	_, err = store.Load(1)
+	assert.True(t, errors.Is(err, lvlup.ErrProxmoNotCached), "Error should be ErrProxmoNotCached")
+
+	err = store.Save(1, &lvlup.ProxmoUser{Password: "plaintext-password", Url: "https://proxmo.example", Username: "user"})
+	assert.Nil(t, err, "Error should be nil")
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err, "Error should be nil")
+	assert.False(t, bytes.Contains(content, []byte("plaintext-password")), "Store should be encrypted")
+
+	reopened, err := lvlup.NewEncryptedProxmoStore(path, proxmoKey)
+	assert.Nil(t, err, "Error should be nil")
+
+	user, err := reopened.Load(1)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "plaintext-password", user.Password.Reveal())
+	assert.Equal(t, "user", user.Username)
+
+	wrongKey, err := lvlup.NewEncryptedProxmoStore(path, []byte("fedcba9876543210fedcba9876543210"))
+	assert.Nil(t, err, "Error should be nil")
+
+	_, err = wrongKey.Load(1)
+	assert.NotNil(t, err, "Error should not be nil")
+
+	_, err = lvlup.NewEncryptedProxmoStore(path, []byte("short"))
+	assert.NotNil(t, err, "Error should not be nil")
+}
+
+func Test_json_lines_audit_log(t *testing.T) {
+	var buf bytes.Buffer
+	log := lvlup.NewJSONLinesAuditLog(&buf)
+
+	log.Audit(lvlup.ProxmoAuditEntry{VPSId: 1, Requester: "alice", Action: lvlup.ProxmoIssued})
+	log.Audit(lvlup.ProxmoAuditEntry{VPSId: 1, Requester: "bob", Action: lvlup.ProxmoCached, Error: "boom"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"requester":"alice"`)
+	assert.Contains(t, lines[1], `"error":"boom"`)
+}
+
+// failingWriter represents writer which can't write anything.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("read-only file system")
+}
+
+func Test_json_lines_audit_log_write_error(t *testing.T) {
+	var failed []lvlup.ProxmoAuditEntry
+	log := lvlup.NewJSONLinesAuditLog(failingWriter{}, lvlup.WithAuditErrorHandler(func(entry lvlup.ProxmoAuditEntry, err error) {
+		assert.NotNil(t, err, "Error should not be nil")
+		failed = append(failed, entry)
+	}))
+
+	log.Audit(lvlup.ProxmoAuditEntry{VPSId: 1, Requester: "alice", Action: lvlup.ProxmoIssued})
+
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "alice", failed[0].Requester)
+}
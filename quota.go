@@ -0,0 +1,180 @@
+package lvlup
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota represents rate limit of an endpoint group as reported by the api.
+type Quota struct {
+	// Limit is the number of requests allowed in a window, zero if not reported.
+	Limit int
+	// Remaining is the number of requests left in the window. Requests sent
+	// since the last response are already subtracted.
+	Remaining int
+	// Reset is the time at which the window ends, zero if not reported.
+	Reset time.Time
+	// UpdatedAt is the time of the response the quota was read from.
+	UpdatedAt time.Time
+}
+
+// resetTimestamp separates reset headers holding unix time from ones holding seconds left.
+const resetTimestamp = 1e9
+
+// Quota returns the latest quota of the endpoint group, for example "wallet" or "services".
+// Groups are named after the first segment of the endpoint path.
+// It returns false if the api didn't report any quota of the group yet.
+func (lc LvlClient) Quota(group string) (Quota, bool) {
+	if lc.quotas == nil {
+		return Quota{}, false
+	}
+
+	lc.quotas.mu.Lock()
+	defer lc.quotas.mu.Unlock()
+
+	quota, ok := lc.quotas.groups[group]
+	return quota, ok
+}
+
+// endpointGroup returns group of the endpoint path.
+func endpointGroup(path string) string {
+	group, _, _ := cutString(strings.TrimPrefix(path, "/"), "/")
+	return group
+}
+
+// quotaTracker represents quotas of endpoint groups shared by copies of a client.
+type quotaTracker struct {
+	mu     sync.Mutex
+	groups map[string]Quota
+}
+
+// newQuotaTracker creates new tracker without any quotas.
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{
+		groups: map[string]Quota{},
+	}
+}
+
+// wait blocks until a request of the group can be made without exceeding its quota.
+// When the quota is used up, it waits for the reset. When less than a quarter
+// of the limit is left, requests are spread evenly until the reset.
+// It returns ctx error if ctx is done before the request can be made.
+func (qt *quotaTracker) wait(ctx context.Context, group string) error {
+	if qt == nil {
+		return ctx.Err()
+	}
+
+	qt.mu.Lock()
+
+	quota, ok := qt.groups[group]
+	delay := quota.delay(time.Now())
+
+	if ok && !quota.Reset.IsZero() {
+		quota.Remaining--
+		qt.groups[group] = quota
+	}
+
+	qt.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// delay returns time to wait before the next request of the group.
+func (qt *quotaTracker) delay(group string) time.Duration {
+	if qt == nil {
+		return 0
+	}
+
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	return qt.groups[group].delay(time.Now())
+}
+
+// update stores quota of the group reported by the response.
+// Responses with 429 Too Many Requests mark the quota as used up.
+func (qt *quotaTracker) update(group string, response *http.Response) {
+	if qt == nil {
+		return
+	}
+
+	now := time.Now()
+	quota, ok := parseQuota(response.Header, now)
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		quota.Remaining = 0
+
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			quota.Reset = now.Add(time.Duration(seconds) * time.Second)
+		}
+	} else if !ok {
+		return
+	}
+
+	qt.mu.Lock()
+	defer qt.mu.Unlock()
+
+	qt.groups[group] = quota
+}
+
+// delay returns time to wait before the next request at now.
+func (q Quota) delay(now time.Time) time.Duration {
+	untilReset := q.Reset.Sub(now)
+
+	if q.Reset.IsZero() || untilReset <= 0 {
+		return 0
+	}
+
+	switch {
+	case q.Remaining <= 0:
+		return untilReset
+	case q.Limit > 0 && q.Remaining*4 < q.Limit:
+		return untilReset / time.Duration(q.Remaining+1)
+	}
+
+	return 0
+}
+
+// parseQuota reads rate limit headers of the response.
+// Both X-RateLimit-* and RateLimit-* headers are supported. Reset may hold
+// either seconds left or unix time.
+// It returns false if the response doesn't report remaining requests.
+func parseQuota(header http.Header, now time.Time) (Quota, bool) {
+	remaining, err := strconv.Atoi(rateLimitHeader(header, "Remaining"))
+
+	if err != nil {
+		return Quota{}, false
+	}
+
+	quota := Quota{
+		Remaining: remaining,
+		UpdatedAt: now,
+	}
+
+	if limit, err := strconv.Atoi(rateLimitHeader(header, "Limit")); err == nil {
+		quota.Limit = limit
+	}
+
+	if reset, err := strconv.ParseFloat(rateLimitHeader(header, "Reset"), 64); err == nil && reset >= 0 {
+		if reset >= resetTimestamp {
+			quota.Reset = time.Unix(0, int64(reset*float64(time.Second)))
+		} else {
+			quota.Reset = now.Add(time.Duration(reset * float64(time.Second)))
+		}
+	}
+
+	return quota, true
+}
+
+// rateLimitHeader returns value of X-RateLimit-name or RateLimit-name header.
+func rateLimitHeader(header http.Header, name string) string {
+	if value := header.Get("X-RateLimit-" + name); value != "" {
+		return value
+	}
+
+	return header.Get("RateLimit-" + name)
+}
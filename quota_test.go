@@ -0,0 +1,111 @@
+package lvlup_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// quotaHandler responds with wallet balance and rate limit headers.
+func quotaHandler(status int, headers map[string]string, requests *int) testutil.RoundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		*requests++
+
+		response, err := testutil.JSON(status, lvlup.WalletBalanceResult{BalancePlnInt: 1})(r)
+		response.Header = http.Header{}
+
+		for key, value := range headers {
+			response.Header.Set(key, value)
+		}
+
+		return response, err
+	}
+}
+
+func Test_quota_from_headers(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", quotaHandler(http.StatusOK, map[string]string{
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "99",
+		"X-RateLimit-Reset":     "60",
+	}, &requests))
+
+	_, ok := client.Quota("wallet")
+	assert.False(t, ok, "Quota should not be known before a request")
+
+	_, err := client.WalletBalance()
+	assert.Nil(t, err, "Error should be nil")
+
+	quota, ok := client.Quota("wallet")
+
+	assert.True(t, ok, "Quota should be known")
+	assert.Equal(t, 100, quota.Limit)
+	assert.Equal(t, 99, quota.Remaining)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), quota.Reset, time.Second)
+
+	_, ok = client.Quota("services")
+	assert.False(t, ok, "Quota of other groups should not be known")
+}
+
+func Test_quota_waits_for_reset(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", quotaHandler(http.StatusOK, map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "0.1",
+	}, &requests))
+
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.WalletBalance()
+		assert.Nil(t, err, "Error should be nil")
+	}
+
+	assert.Equal(t, 2, requests)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(80*time.Millisecond), "Second request should wait for the reset")
+}
+
+func Test_quota_wait_canceled(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", quotaHandler(http.StatusOK, map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+	}, &requests))
+
+	_, err := client.WalletBalance()
+	assert.Nil(t, err, "Error should be nil")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = client.WithContext(ctx).WalletBalance()
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, requests, "Request should not be sent")
+	assert.Less(t, int64(time.Since(start)), int64(time.Second), "Wait for the reset should be interrupted")
+}
+
+func Test_quota_too_many_requests(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", quotaHandler(http.StatusTooManyRequests, map[string]string{
+		"Retry-After": "30",
+	}, &requests))
+
+	_, err := client.WalletBalance()
+	assert.NotNil(t, err, "Error should not be nil")
+
+	quota, ok := client.Quota("wallet")
+
+	assert.True(t, ok, "Quota should be known")
+	assert.Equal(t, 0, quota.Remaining)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), quota.Reset, time.Second)
+}
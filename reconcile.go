@@ -0,0 +1,188 @@
+package lvlup
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ReconcileRecord represents a locally stored payment expected to be settled.
+// ExpectedPlnInt is the expected amount in grosz.
+type ReconcileRecord struct {
+	OrderId        string
+	PaymentId      PaymentID
+	ExpectedPlnInt int
+}
+
+// ReconcileStatus represents outcome of reconciling a single record.
+type ReconcileStatus int
+
+const (
+	ReconcilePaid ReconcileStatus = iota
+	ReconcileUnpaid
+	ReconcileMissing
+	ReconcileAmountMismatch
+	ReconcileFailed
+)
+
+// String returns name of the status.
+func (rs ReconcileStatus) String() string {
+	switch rs {
+	case ReconcilePaid:
+		return "paid"
+	case ReconcileUnpaid:
+		return "unpaid"
+	case ReconcileMissing:
+		return "missing"
+	case ReconcileAmountMismatch:
+		return "amount mismatch"
+	case ReconcileFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconcileEntry represents result of reconciling a single record.
+type ReconcileEntry struct {
+	Record  ReconcileRecord
+	Status  ReconcileStatus
+	Payment *InspectPaymentResult
+	Err     error
+}
+
+// ReconcileReport represents result of Reconcile func.
+// Entries are in the same order as reconciled records.
+type ReconcileReport struct {
+	Entries []ReconcileEntry
+}
+
+// ByStatus returns entries with specified status.
+func (rr *ReconcileReport) ByStatus(status ReconcileStatus) []ReconcileEntry {
+	var entries []ReconcileEntry
+
+	for _, entry := range rr.Entries {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// Settled reports whether every record was paid with the expected amount.
+func (rr *ReconcileReport) Settled() bool {
+	return len(rr.ByStatus(ReconcilePaid)) == len(rr.Entries)
+}
+
+// ReconcileOptions represents available options for Reconcile func.
+type ReconcileOptions struct {
+	Concurrency int
+	FeeIncluded bool
+}
+
+// ReconcileOption represents a functional option for Reconcile func.
+type ReconcileOption func(*ReconcileOptions)
+
+// WithReconcileConcurrency sets how many payments are inspected at the same time.
+func WithReconcileConcurrency(concurrency int) ReconcileOption {
+	return func(ro *ReconcileOptions) {
+		ro.Concurrency = concurrency
+	}
+}
+
+// WithFeeIncluded compares expected amounts with AmountWithFeeInt instead of AmountInt.
+func WithFeeIncluded() ReconcileOption {
+	return func(ro *ReconcileOptions) {
+		ro.FeeIncluded = true
+	}
+}
+
+// Reconcile allows to check whether local records were settled in LvlUp.
+// Every payment is inspected with InspectPayment and classified as paid, unpaid,
+// missing, paid with a different amount, or failed when the api call fails.
+// It returns the report and ctx error if ctx was done before all records were checked.
+func (lc LvlClient) Reconcile(ctx context.Context, records []ReconcileRecord, opts ...ReconcileOption) (*ReconcileReport, error) {
+	options := &ReconcileOptions{
+		Concurrency: 4,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	report := &ReconcileReport{
+		Entries: make([]ReconcileEntry, len(records)),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for i := 0; i < options.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range jobs {
+				report.Entries[index] = lc.reconcileRecord(ctx, records[index], options)
+			}
+		}()
+	}
+
+	for i := range records {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+// reconcileRecord inspects payment of a single record.
+func (lc LvlClient) reconcileRecord(ctx context.Context, record ReconcileRecord, options *ReconcileOptions) ReconcileEntry {
+	entry := ReconcileEntry{
+		Record: record,
+	}
+
+	if err := ctx.Err(); err != nil {
+		entry.Status = ReconcileFailed
+		entry.Err = err
+		return entry
+	}
+
+	payment, err := lc.InspectPayment(record.PaymentId)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		entry.Status = ReconcileMissing
+		return entry
+	case err != nil:
+		entry.Status = ReconcileFailed
+		entry.Err = err
+		return entry
+	}
+
+	entry.Payment = payment
+
+	amount := payment.AmountInt
+	if options.FeeIncluded {
+		amount = payment.AmountWithFeeInt
+	}
+
+	switch {
+	case !payment.Payed:
+		entry.Status = ReconcileUnpaid
+	case amount != record.ExpectedPlnInt:
+		entry.Status = ReconcileAmountMismatch
+	default:
+		entry.Status = ReconcilePaid
+	}
+
+	return entry
+}
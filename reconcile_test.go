@@ -0,0 +1,66 @@
+package lvlup_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reconcileHandler() testutil.RoundTripFunc {
+	return testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/wallet/up/paid":     testutil.JSON(http.StatusOK, lvlup.InspectPaymentResult{Payed: true, AmountInt: 1000, AmountWithFeeInt: 1100}),
+		"/v4/wallet/up/unpaid":   testutil.JSON(http.StatusOK, lvlup.InspectPaymentResult{Payed: false, AmountInt: 1000}),
+		"/v4/wallet/up/mismatch": testutil.JSON(http.StatusOK, lvlup.InspectPaymentResult{Payed: true, AmountInt: 500}),
+		"/v4/wallet/up/broken":   testutil.HttpError(http.StatusInternalServerError),
+	})
+}
+
+func Test_reconcile(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", reconcileHandler())
+
+	records := []lvlup.ReconcileRecord{
+		{OrderId: "a", PaymentId: "paid", ExpectedPlnInt: 1000},
+		{OrderId: "b", PaymentId: "unpaid", ExpectedPlnInt: 1000},
+		{OrderId: "c", PaymentId: "gone", ExpectedPlnInt: 1000},
+		{OrderId: "d", PaymentId: "mismatch", ExpectedPlnInt: 1000},
+		{OrderId: "e", PaymentId: "broken", ExpectedPlnInt: 1000},
+	}
+
+	report, err := client.Reconcile(context.Background(), records, lvlup.WithReconcileConcurrency(2))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.False(t, report.Settled())
+
+	statuses := []lvlup.ReconcileStatus{}
+	for _, entry := range report.Entries {
+		statuses = append(statuses, entry.Status)
+	}
+
+	assert.Equal(t, []lvlup.ReconcileStatus{
+		lvlup.ReconcilePaid,
+		lvlup.ReconcileUnpaid,
+		lvlup.ReconcileMissing,
+		lvlup.ReconcileAmountMismatch,
+		lvlup.ReconcileFailed,
+	}, statuses)
+
+	assert.NotNil(t, report.Entries[4].Err)
+	assert.Len(t, report.ByStatus(lvlup.ReconcileMissing), 1)
+	assert.Equal(t, "c", report.ByStatus(lvlup.ReconcileMissing)[0].Record.OrderId)
+}
+
+func Test_reconcile_with_fee_included(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", reconcileHandler())
+
+	records := []lvlup.ReconcileRecord{{OrderId: "a", PaymentId: "paid", ExpectedPlnInt: 1100}}
+
+	report, err := client.Reconcile(context.Background(), records, lvlup.WithFeeIncluded())
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.True(t, report.Settled())
+}
@@ -0,0 +1,243 @@
+package lvlup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RenewalNotice represents a notification about a service approaching the end of its payed period.
+type RenewalNotice struct {
+	Service   Service
+	PayedTo   time.Time
+	Left      time.Duration
+	Threshold time.Duration
+}
+
+// FundsNotice represents a warning that the wallet can't cover upcoming renewals.
+type FundsNotice struct {
+	BalancePlnInt  int
+	RequiredPlnInt int
+	Services       []Service
+}
+
+// RenewalNotifier describes receiver of notifications emitted by RenewalWatcher.
+type RenewalNotifier interface {
+	RenewalDue(notice RenewalNotice)
+	InsufficientFunds(notice FundsNotice)
+}
+
+// RenewalWatcherOptions represents available options for RenewalWatcher.
+type RenewalWatcherOptions struct {
+	Interval     time.Duration
+	Thresholds   []time.Duration
+	RenewalCost  func(Service) int
+	ErrorHandler func(error)
+}
+
+// RenewalWatcherOption represents a functional option for RenewalWatcher.
+type RenewalWatcherOption func(*RenewalWatcherOptions)
+
+// WithRenewalInterval sets how often services are checked.
+// Non-positive intervals are replaced with the default one.
+func WithRenewalInterval(interval time.Duration) RenewalWatcherOption {
+	return func(rwo *RenewalWatcherOptions) {
+		rwo.Interval = interval
+	}
+}
+
+// WithRenewalThresholds sets how long before the end of payed period notifications are emitted.
+func WithRenewalThresholds(thresholds ...time.Duration) RenewalWatcherOption {
+	return func(rwo *RenewalWatcherOptions) {
+		rwo.Thresholds = thresholds
+	}
+}
+
+// WithRenewalCost enables checking whether the wallet covers upcoming renewals.
+// The func should return renewal cost of a service in grosz.
+func WithRenewalCost(cost func(Service) int) RenewalWatcherOption {
+	return func(rwo *RenewalWatcherOptions) {
+		rwo.RenewalCost = cost
+	}
+}
+
+// WithRenewalErrorHandler sets func called with errors encountered by Run.
+func WithRenewalErrorHandler(handler func(error)) RenewalWatcherOption {
+	return func(rwo *RenewalWatcherOptions) {
+		rwo.ErrorHandler = handler
+	}
+}
+
+// RenewalWatcher periodically checks services and notifies about their upcoming expiry.
+type RenewalWatcher struct {
+	client   *LvlClient
+	notifier RenewalNotifier
+	options  *RenewalWatcherOptions
+
+	mu           sync.Mutex
+	notified     map[ServiceID]time.Duration
+	payedTo      map[ServiceID]time.Time
+	lastShortage *FundsNotice
+}
+
+// defaultRenewalInterval is how often services are checked if no valid interval is set.
+const defaultRenewalInterval = time.Hour
+
+// NewRenewalWatcher creates new watcher sending notifications to the notifier.
+// By default services are checked every hour and notifications are emitted 30, 7 and 1 day before expiry.
+func NewRenewalWatcher(client *LvlClient, notifier RenewalNotifier, opts ...RenewalWatcherOption) *RenewalWatcher {
+	options := &RenewalWatcherOptions{
+		Interval:   defaultRenewalInterval,
+		Thresholds: []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour},
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = defaultRenewalInterval
+	}
+
+	thresholds := append([]time.Duration(nil), options.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	options.Thresholds = thresholds
+
+	return &RenewalWatcher{
+		client:   client,
+		notifier: notifier,
+		options:  options,
+		notified: map[ServiceID]time.Duration{},
+		payedTo:  map[ServiceID]time.Time{},
+	}
+}
+
+// Run checks services every interval until ctx is done.
+// It returns ctx error.
+func (rw *RenewalWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(rw.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := rw.Check(); err != nil && rw.options.ErrorHandler != nil {
+			rw.options.ErrorHandler(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check lists services once and emits notifications for crossed thresholds.
+// Each threshold is reported once per payed period of a service.
+// It returns any errors encountered.
+func (rw *RenewalWatcher) Check() error {
+	services, err := rw.client.ListServices(WithActive(true))
+
+	if err != nil {
+		return err
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	now := time.Now()
+	var upcoming []Service
+
+	for _, service := range services.Services {
+		payedTo, err := service.PayedToTime()
+
+		if err != nil {
+			continue
+		}
+
+		if previous, ok := rw.payedTo[service.Id]; ok && !previous.Equal(payedTo) {
+			delete(rw.notified, service.Id)
+		}
+
+		rw.payedTo[service.Id] = payedTo
+		left := payedTo.Sub(now)
+
+		threshold, ok := rw.crossedThreshold(left)
+
+		if !ok {
+			continue
+		}
+
+		upcoming = append(upcoming, service)
+
+		if notified, ok := rw.notified[service.Id]; ok && notified <= threshold {
+			continue
+		}
+
+		rw.notified[service.Id] = threshold
+		rw.notifier.RenewalDue(RenewalNotice{
+			Service:   service,
+			PayedTo:   payedTo,
+			Left:      left,
+			Threshold: threshold,
+		})
+	}
+
+	if rw.options.RenewalCost == nil {
+		return nil
+	}
+
+	return rw.checkFunds(upcoming)
+}
+
+// crossedThreshold returns the smallest threshold not shorter than left time.
+func (rw *RenewalWatcher) crossedThreshold(left time.Duration) (time.Duration, bool) {
+	for _, threshold := range rw.options.Thresholds {
+		if left <= threshold {
+			return threshold, true
+		}
+	}
+
+	return 0, false
+}
+
+// checkFunds compares wallet balance with renewal cost of upcoming services.
+func (rw *RenewalWatcher) checkFunds(upcoming []Service) error {
+	if len(upcoming) == 0 {
+		rw.lastShortage = nil
+		return nil
+	}
+
+	required := 0
+	for _, service := range upcoming {
+		required += rw.options.RenewalCost(service)
+	}
+
+	wallet, err := rw.client.WalletBalance()
+
+	if err != nil {
+		return err
+	}
+
+	if wallet.BalancePlnInt >= required {
+		rw.lastShortage = nil
+		return nil
+	}
+
+	notice := FundsNotice{
+		BalancePlnInt:  wallet.BalancePlnInt,
+		RequiredPlnInt: required,
+		Services:       upcoming,
+	}
+
+	if rw.lastShortage != nil &&
+		rw.lastShortage.BalancePlnInt == notice.BalancePlnInt &&
+		rw.lastShortage.RequiredPlnInt == notice.RequiredPlnInt {
+		return nil
+	}
+
+	rw.lastShortage = &notice
+	rw.notifier.InsufficientFunds(notice)
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package lvlup_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	renewals []lvlup.RenewalNotice
+	funds    []lvlup.FundsNotice
+}
+
+func (rn *recordingNotifier) RenewalDue(notice lvlup.RenewalNotice) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.renewals = append(rn.renewals, notice)
+}
+
+func (rn *recordingNotifier) InsufficientFunds(notice lvlup.FundsNotice) {
+	rn.mu.Lock()
+	defer rn.mu.Unlock()
+
+	rn.funds = append(rn.funds, notice)
+}
+
+func payedIn(d time.Duration) string {
+	return time.Now().Add(d).UTC().Format(time.RFC3339)
+}
+
+func Test_renewal_watcher_thresholds(t *testing.T) {
+	day := 24 * time.Hour
+	services := []lvlup.Service{
+		{Id: 1, PlanName: "VPS", Active: true, PayedTo: payedIn(20 * day)},
+		{Id: 2, PlanName: "VPS", Active: true, PayedTo: payedIn(5 * day)},
+		{Id: 3, PlanName: "VPS", Active: true, PayedTo: payedIn(60 * day)},
+		{Id: 4, PlanName: "VPS", Active: false, PayedTo: payedIn(5 * day)},
+	}
+
+	handler := func(r *http.Request) (*http.Response, error) {
+		return testutil.JSON(http.StatusOK, lvlup.ListServicesResult{Services: services})(r)
+	}
+
+	notifier := &recordingNotifier{}
+	watcher := lvlup.NewRenewalWatcher(testutil.NewTestLvlClient("token", handler), notifier)
+
+	assert.Nil(t, watcher.Check())
+	assert.Len(t, notifier.renewals, 2)
+	assert.Equal(t, 30*day, notifier.renewals[0].Threshold)
+	assert.Equal(t, 7*day, notifier.renewals[1].Threshold)
+
+	assert.Nil(t, watcher.Check())
+	assert.Len(t, notifier.renewals, 2, "Thresholds should be reported once")
+
+	services[0].PayedTo = payedIn(12 * time.Hour)
+	assert.Nil(t, watcher.Check())
+	assert.Len(t, notifier.renewals, 3)
+	assert.Equal(t, day, notifier.renewals[2].Threshold)
+}
+
+func Test_renewal_watcher_insufficient_funds(t *testing.T) {
+	handler := testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/services": testutil.JSON(http.StatusOK, lvlup.ListServicesResult{
+			Services: []lvlup.Service{
+				{Id: 1, PlanName: "VPS", Active: true, PayedTo: payedIn(48 * time.Hour)},
+				{Id: 2, PlanName: "VPS", Active: true, PayedTo: payedIn(72 * time.Hour)},
+			},
+		}),
+		"/v4/wallet": testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: 1500}),
+	})
+
+	notifier := &recordingNotifier{}
+	watcher := lvlup.NewRenewalWatcher(
+		testutil.NewTestLvlClient("token", handler),
+		notifier,
+		lvlup.WithRenewalCost(func(lvlup.Service) int { return 1000 }),
+	)
+
+	assert.Nil(t, watcher.Check())
+	assert.Len(t, notifier.funds, 1)
+	assert.Equal(t, 2000, notifier.funds[0].RequiredPlnInt)
+	assert.Equal(t, 1500, notifier.funds[0].BalancePlnInt)
+
+	assert.Nil(t, watcher.Check())
+	assert.Len(t, notifier.funds, 1, "Unchanged shortage should be reported once")
+}
+
+func Test_renewal_watcher_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	watcher := lvlup.NewRenewalWatcher(client, &recordingNotifier{})
+
+	assert.NotNil(t, watcher.Check(), "Error should not be nil")
+}
+
+func Test_renewal_watcher_run(t *testing.T) {
+	errs := make(chan error, 1)
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	watcher := lvlup.NewRenewalWatcher(
+		client,
+		&recordingNotifier{},
+		lvlup.WithRenewalInterval(time.Millisecond),
+		lvlup.WithRenewalErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	assert.NotNil(t, <-errs, "Error should be reported")
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func Test_renewal_watcher_invalid_interval(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	watcher := lvlup.NewRenewalWatcher(client, &recordingNotifier{}, lvlup.WithRenewalInterval(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, context.Canceled, watcher.Run(ctx))
+	})
+}
@@ -0,0 +1,360 @@
+// Package reports builds revenue reports from LvlUp payments.
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	// Embedded time zone database makes Europe/Warsaw available on hosts without zoneinfo.
+	_ "time/tzdata"
+
+	"github.com/senicko/lvlup"
+)
+
+// Period represents length of a report bucket.
+type Period int
+
+const (
+	Daily Period = iota
+	Weekly
+	Monthly
+)
+
+// String returns name of the period.
+func (p Period) String() string {
+	switch p {
+	case Daily:
+		return "daily"
+	case Weekly:
+		return "weekly"
+	case Monthly:
+		return "monthly"
+	default:
+		return "unknown"
+	}
+}
+
+// start returns start of the bucket containing t, in t's location.
+// Weeks start on Monday.
+func (p Period) start(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	switch p {
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// next returns start of the bucket following the one starting at start.
+func (p Period) next(start time.Time) time.Time {
+	switch p {
+	case Weekly:
+		return start.AddDate(0, 0, 7)
+	case Monthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Totals represents number and sum of payments. Amounts are in grosz.
+type Totals struct {
+	Count         int `json:"count"`
+	TotalPlnInt   int `json:"totalPlnInt"`
+	AveragePlnInt int `json:"averagePlnInt"`
+}
+
+// add includes a single payment.
+func (t *Totals) add(amount int) {
+	t.Count++
+	t.TotalPlnInt += amount
+	t.AveragePlnInt = t.TotalPlnInt / t.Count
+}
+
+// ServiceTotals represents totals of payments for a single service.
+type ServiceTotals struct {
+	ServiceId lvlup.ServiceID `json:"serviceId"`
+	Totals
+}
+
+// Bucket represents payments created in [Start, End) range.
+type Bucket struct {
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Services []ServiceTotals `json:"services"`
+	Totals
+}
+
+// Report represents revenue report.
+// Buckets are sorted from the oldest and only buckets with payments are included.
+type Report struct {
+	Period   Period          `json:"period"`
+	Location *time.Location  `json:"-"`
+	Buckets  []Bucket        `json:"buckets"`
+	Services []ServiceTotals `json:"services"`
+	Total    Totals          `json:"total"`
+}
+
+// Options represents available options for building a report.
+type Options struct {
+	Location *time.Location
+}
+
+// Option represents a functional option for building a report.
+type Option func(*Options)
+
+// WithLocation sets time zone in which payments are bucketed.
+func WithLocation(location *time.Location) Option {
+	return func(o *Options) {
+		o.Location = location
+	}
+}
+
+// Aggregator builds a report from payments added one by one.
+type Aggregator struct {
+	report   *Report
+	buckets  map[time.Time]*bucketState
+	services map[lvlup.ServiceID]*ServiceTotals
+}
+
+// bucketState represents bucket being aggregated.
+type bucketState struct {
+	bucket   Bucket
+	services map[lvlup.ServiceID]*ServiceTotals
+}
+
+// NewAggregator creates new aggregator bucketing payments by period.
+// By default payments are bucketed in Europe/Warsaw time zone.
+// It returns any errors encountered while loading the time zone.
+func NewAggregator(period Period, opts ...Option) (*Aggregator, error) {
+	options := &Options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Location == nil {
+		location, err := time.LoadLocation("Europe/Warsaw")
+
+		if err != nil {
+			return nil, err
+		}
+
+		options.Location = location
+	}
+
+	return &Aggregator{
+		report: &Report{
+			Period:   period,
+			Location: options.Location,
+		},
+		buckets:  map[time.Time]*bucketState{},
+		services: map[lvlup.ServiceID]*ServiceTotals{},
+	}, nil
+}
+
+// Add includes a payment in the report.
+// It returns any errors encountered while parsing the payment.
+func (a *Aggregator) Add(item lvlup.ListPaymentsResultItem) error {
+	createdAt, err := item.CreatedAtTime()
+
+	if err != nil {
+		return fmt.Errorf("payment %d: %w", item.Id, err)
+	}
+
+	amount, err := item.AmountPlnInt()
+
+	if err != nil {
+		return fmt.Errorf("payment %d: %w", item.Id, err)
+	}
+
+	start := a.report.Period.start(createdAt.In(a.report.Location))
+
+	state, ok := a.buckets[start]
+	if !ok {
+		state = &bucketState{
+			bucket: Bucket{
+				Start: start,
+				End:   a.report.Period.next(start),
+			},
+			services: map[lvlup.ServiceID]*ServiceTotals{},
+		}
+
+		a.buckets[start] = state
+	}
+
+	state.bucket.add(amount)
+	addService(state.services, item.ServiceId, amount)
+	addService(a.services, item.ServiceId, amount)
+	a.report.Total.add(amount)
+
+	return nil
+}
+
+// addService includes amount in totals of the service.
+func addService(services map[lvlup.ServiceID]*ServiceTotals, serviceId lvlup.ServiceID, amount int) {
+	if _, ok := services[serviceId]; !ok {
+		services[serviceId] = &ServiceTotals{ServiceId: serviceId}
+	}
+
+	services[serviceId].add(amount)
+}
+
+// sortedServices returns service totals sorted by service id.
+func sortedServices(services map[lvlup.ServiceID]*ServiceTotals) []ServiceTotals {
+	result := make([]ServiceTotals, 0, len(services))
+
+	for _, service := range services {
+		result = append(result, *service)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ServiceId < result[j].ServiceId })
+
+	return result
+}
+
+// Report returns the report of payments added so far.
+func (a *Aggregator) Report() *Report {
+	report := *a.report
+	report.Buckets = make([]Bucket, 0, len(a.buckets))
+
+	for _, state := range a.buckets {
+		bucket := state.bucket
+		bucket.Services = sortedServices(state.services)
+		report.Buckets = append(report.Buckets, bucket)
+	}
+
+	sort.Slice(report.Buckets, func(i, j int) bool { return report.Buckets[i].Start.Before(report.Buckets[j].Start) })
+	report.Services = sortedServices(a.services)
+
+	return &report
+}
+
+// Build allows to build a report from payments.
+// It returns the report and any errors encountered.
+func Build(items []lvlup.ListPaymentsResultItem, period Period, opts ...Option) (*Report, error) {
+	aggregator, err := NewAggregator(period, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err := aggregator.Add(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return aggregator.Report(), nil
+}
+
+// FromClient allows to build a report from all client's payments.
+// Payments are fetched page by page, so they are never all kept in memory.
+// It returns the report and any errors encountered.
+func FromClient(client *lvlup.LvlClient, period Period, opts ...Option) (*Report, error) {
+	aggregator, err := NewAggregator(period, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.EachPayment(100, aggregator.Add); err != nil {
+		return nil, err
+	}
+
+	return aggregator.Report(), nil
+}
+
+// formatAmount formats grosz as amount like "24.99".
+func formatAmount(grosz int) string {
+	sign := ""
+	if grosz < 0 {
+		sign = "-"
+		grosz = -grosz
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, grosz/100, grosz%100)
+}
+
+// label returns human readable name of the bucket.
+func (r *Report) label(bucket Bucket) string {
+	switch r.Period {
+	case Monthly:
+		return bucket.Start.Format("2006-01")
+	case Weekly:
+		year, week := bucket.Start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return bucket.Start.Format("2006-01-02")
+	}
+}
+
+// WriteCSV writes the report to w as csv, one row per bucket and service.
+// Rows with empty service column hold totals of the whole bucket.
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"period", "start", "serviceId", "count", "total", "average"}); err != nil {
+		return err
+	}
+
+	row := func(bucket Bucket, serviceId string, totals Totals) error {
+		return writer.Write([]string{
+			r.label(bucket),
+			bucket.Start.Format(time.RFC3339),
+			serviceId,
+			strconv.Itoa(totals.Count),
+			formatAmount(totals.TotalPlnInt),
+			formatAmount(totals.AveragePlnInt),
+		})
+	}
+
+	for _, bucket := range r.Buckets {
+		if err := row(bucket, "", bucket.Totals); err != nil {
+			return err
+		}
+
+		for _, service := range bucket.Services {
+			if err := row(bucket, service.ServiceId.String(), service.Totals); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTable writes the report to w as a plain-text table, suitable for chat messages.
+func (r *Report) WriteTable(w io.Writer) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(writer, "Period\tCount\tTotal PLN\tAverage PLN\t")
+
+	for _, bucket := range r.Buckets {
+		fmt.Fprintf(writer, "%s\t%d\t%s\t%s\t\n",
+			r.label(bucket),
+			bucket.Count,
+			formatAmount(bucket.TotalPlnInt),
+			formatAmount(bucket.AveragePlnInt),
+		)
+	}
+
+	fmt.Fprintf(writer, "Total\t%d\t%s\t%s\t\n",
+		r.Total.Count,
+		formatAmount(r.Total.TotalPlnInt),
+		formatAmount(r.Total.AveragePlnInt),
+	)
+
+	return writer.Flush()
+}
@@ -0,0 +1,105 @@
+package reports_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+	"github.com/senicko/lvlup/reports"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func payments() []lvlup.ListPaymentsResultItem {
+	return []lvlup.ListPaymentsResultItem{
+		// 2021-05-31 23:30 UTC is already June 1st in Warsaw.
+		{Id: 1, Amount: "10.00", CreatedAt: "2021-05-31T23:30:00Z", ServiceId: 1},
+		{Id: 2, Amount: "20.00", CreatedAt: "2021-06-01T12:00:00Z", ServiceId: 2},
+		{Id: 3, Amount: "5.00", CreatedAt: "2021-06-07T08:00:00Z", ServiceId: 1},
+		{Id: 4, Amount: "1.50", CreatedAt: "2021-07-01T08:00:00Z", ServiceId: 1},
+	}
+}
+
+func Test_daily_report_in_warsaw(t *testing.T) {
+	report, err := reports.Build(payments(), reports.Daily)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, report.Buckets, 3)
+
+	first := report.Buckets[0]
+	assert.Equal(t, "2021-06-01", first.Start.Format("2006-01-02"))
+	assert.Equal(t, 2, first.Count)
+	assert.Equal(t, 3000, first.TotalPlnInt)
+	assert.Equal(t, 1500, first.AveragePlnInt)
+	assert.Len(t, first.Services, 2)
+
+	assert.Equal(t, 4, report.Total.Count)
+	assert.Equal(t, 3650, report.Total.TotalPlnInt)
+}
+
+func Test_daily_report_in_utc(t *testing.T) {
+	report, err := reports.Build(payments(), reports.Daily, reports.WithLocation(time.UTC))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, report.Buckets, 4)
+	assert.Equal(t, "2021-05-31", report.Buckets[0].Start.Format("2006-01-02"))
+}
+
+func Test_weekly_and_monthly_reports(t *testing.T) {
+	weekly, err := reports.Build(payments(), reports.Weekly)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, weekly.Buckets, 3)
+	assert.Equal(t, time.Monday, weekly.Buckets[0].Start.Weekday())
+	assert.Equal(t, weekly.Buckets[0].Start.AddDate(0, 0, 7), weekly.Buckets[0].End)
+
+	monthly, err := reports.Build(payments(), reports.Monthly)
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, monthly.Buckets, 2)
+	assert.Equal(t, 3, monthly.Buckets[0].Count)
+	assert.Equal(t, []reports.ServiceTotals{
+		{ServiceId: 1, Totals: reports.Totals{Count: 3, TotalPlnInt: 1650, AveragePlnInt: 550}},
+		{ServiceId: 2, Totals: reports.Totals{Count: 1, TotalPlnInt: 2000, AveragePlnInt: 2000}},
+	}, monthly.Services)
+}
+
+func Test_report_outputs(t *testing.T) {
+	report, err := reports.Build(payments(), reports.Monthly)
+	assert.Nil(t, err, "Error should be nil")
+
+	var csvOut bytes.Buffer
+	assert.Nil(t, report.WriteCSV(&csvOut))
+
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"2021-06", "2021-06-01T00:00:00+02:00", "", "3", "35.00", "11.66"}, rows[1])
+
+	var table bytes.Buffer
+	assert.Nil(t, report.WriteTable(&table))
+
+	lines := strings.Split(strings.TrimRight(table.String(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.Contains(t, lines[1], "2021-06")
+	assert.Contains(t, lines[3], "36.50")
+}
+
+func Test_report_from_client(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.PaymentsPager(payments()))
+
+	report, err := reports.FromClient(client, reports.Monthly)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 4, report.Total.Count)
+}
+
+func Test_report_from_client_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+
+	_, err := reports.FromClient(client, reports.Monthly)
+
+	assert.NotNil(t, err, "Error should not be nil")
+}
@@ -2,15 +2,18 @@ package lvlup
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 )
 
 // requestOptions represents options for http request.
 type requestOptions struct {
 	Headers map[string]string
 	Query   map[string]string
-	Body    io.Reader
+	Body    []byte
 }
 
 type requestOption func(*requestOptions)
@@ -32,15 +35,13 @@ func withQuery(query map[string]string) requestOption {
 // withBody allows to set a body for a request.
 func withBody(body []byte) requestOption {
 	return func(r *requestOptions) {
-		r.Body = bytes.NewBuffer(body)
+		r.Body = body
 	}
 }
 
 // newRequestOptions creates new requestOptions with applied settings.
 func newRequestOptions(opts ...requestOption) *requestOptions {
-	requestOptions := &requestOptions{
-		Body: http.NoBody,
-	}
+	requestOptions := &requestOptions{}
 
 	for _, opt := range opts {
 		opt(requestOptions)
@@ -49,15 +50,212 @@ func newRequestOptions(opts ...requestOption) *requestOptions {
 	return requestOptions
 }
 
-// request allows to make a request to specified url.
+// maxDrainSize limits how much of an unread response body is read to reuse the connection.
+const maxDrainSize = 64 << 10
+
+// operation describes a single api endpoint.
+type operation struct {
+	// Name identifies the operation in errors and schema drift reports.
+	Name   string
+	Method string
+	Path   string
+	// Status is the expected response status, http.StatusOK if zero.
+	Status int
+}
+
+// call executes the operation. The body, if not nil, is sent encoded as json.
+// Response with expected status is decoded into result, unless result is nil.
+// Other responses result in APIError.
+// It returns any errors encountered.
+func (lc LvlClient) call(op operation, body interface{}, result interface{}, opts ...requestOption) error {
+	if body != nil {
+		payload, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, withBody(payload))
+	}
+
+	response, err := lc.request(op, opts...)
+
+	if err != nil {
+		return err
+	}
+
+	defer closeBody(response)
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if response.StatusCode != status {
+		return newAPIError(op.Name, response)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return lc.decode(op.Name, response.Body, result)
+}
+
+// closeBody drains and closes the response body, so the connection can be reused.
+func closeBody(response *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainSize))
+	response.Body.Close()
+}
+
+// sleep blocks for the duration or until ctx is done.
+// It returns ctx error if ctx is done first.
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// request allows to make a request of the operation.
+// The request is authorized with the api key from client credentials. If the api
+// responds with 401, credentials are refreshed and the request is retried once
+// with the new key. If refreshing fails, the 401 response results in APIError
+// holding the refresh error. Other failures are retried according to the client RetryPolicy.
+// Rate limit headers of every response update quota of the endpoint group, which
+// delays further requests of the group when it runs low. All waits end when
+// context of the client is done.
 // It returns recieved response and any errors encountered.
-func (lc LvlClient) request(method string, path string, opts ...requestOption) (*http.Response, error) {
+func (lc LvlClient) request(op operation, opts ...requestOption) (*http.Response, error) {
+	if lc.configErr != nil {
+		return nil, lc.configErr
+	}
+
+	ctx := lc.context()
 	requestOptions := newRequestOptions(opts...)
 
-	request, err := http.NewRequest(method, lc.ApiBase+path, requestOptions.Body)
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		response, latency, err := lc.send(op, requestOptions)
+
+		if attempt >= lc.retryPolicy.MaxRetries || !lc.retryPolicy.retryable(op.Method, requestOptions, response, err) {
+			if err == nil && lc.responseMeta != nil {
+				err = lc.responseMeta.capture(response, attempt+1, latency)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			return response, nil
+		}
+
+		delay := lc.retryPolicy.delay(attempt, response)
+
+		// Requests of a group with used up quota wait for the reset anyway.
+		if lc.quotas.delay(endpointGroup(op.Path)) >= delay {
+			delay = 0
+		}
+
+		if response != nil {
+			closeBody(response)
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes a single attempt of the request, refreshing credentials on 401.
+// It returns the response, latency of the last request sent and any errors encountered.
+func (lc LvlClient) send(op operation, requestOptions *requestOptions) (*http.Response, time.Duration, error) {
+	credentials := lc.credentials()
+
+	apiKey, err := credentials.ApiKey()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	response, latency, err := lc.do(op.Method, op.Path, apiKey, requestOptions)
+
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, latency, err
+	}
+
+	refreshed, err := refreshApiKey(credentials)
+
+	if err != nil {
+		apiErr := newAPIError(op.Name, response)
+		apiErr.CredentialsErr = err
+		closeBody(response)
+
+		return nil, latency, apiErr
+	}
+
+	if refreshed == apiKey {
+		return response, latency, nil
+	}
+
+	closeBody(response)
+
+	return lc.do(op.Method, op.Path, refreshed, requestOptions)
+}
+
+// refreshApiKey refreshes credentials and returns the new api key.
+func refreshApiKey(credentials CredentialsProvider) (string, error) {
+	if err := credentials.Refresh(); err != nil {
+		return "", err
+	}
+
+	return credentials.ApiKey()
+}
+
+// do sends a single request authorized with the api key.
+// Latency covers only the time until response headers were received, without
+// waits for the rate limiter and quota.
+func (lc LvlClient) do(method string, path string, apiKey string, requestOptions *requestOptions) (*http.Response, time.Duration, error) {
+	ctx := lc.context()
+	group := endpointGroup(path)
+
+	if lc.limiter != nil {
+		if err := lc.limiter.wait(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := lc.quotas.wait(ctx, group); err != nil {
+		return nil, 0, err
+	}
+
+	var body io.Reader = http.NoBody
+	if requestOptions.Body != nil {
+		body = bytes.NewReader(requestOptions.Body)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, lc.ApiBase+path, body)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+apiKey)
+
+	if requestOptions.Body != nil {
+		request.Header.Set("Content-Type", "application/json")
 	}
 
 	if requestOptions.Headers != nil {
@@ -76,35 +274,15 @@ func (lc LvlClient) request(method string, path string, opts ...requestOption) (
 		request.URL.RawQuery = query.Encode()
 	}
 
+	start := time.Now()
 	response, err := lc.HttpClient.Do(request)
+	latency := time.Since(start)
 
 	if err != nil {
-		return nil, err
+		return nil, latency, err
 	}
 
-	return response, nil
-}
-
-// get is a wrapper for request func.
-// It sends get request to specified url.
-func (lc LvlClient) get(path string, opts ...requestOption) (*http.Response, error) {
-	return lc.request(http.MethodGet, path, opts...)
-}
-
-// post is a wrapper for request func.
-// It sends get request to specified url.
-func (lc LvlClient) post(path string, opts ...requestOption) (*http.Response, error) {
-	return lc.request(http.MethodPost, path, opts...)
-}
-
-// put is a wrapper for request func.
-// It sends put request to specified url.
-func (lc LvlClient) put(path string, opts ...requestOption) (*http.Response, error) {
-	return lc.request(http.MethodPut, path, opts...)
-}
+	lc.quotas.update(group, response)
 
-// delete is a wrapper for request func.
-// It sends delete request to specified url.
-func (lc LvlClient) delete(path string, opts ...requestOption) (*http.Response, error) {
-	return lc.request(http.MethodDelete, path, opts...)
+	return response, latency, nil
 }
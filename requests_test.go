@@ -0,0 +1,106 @@
+package lvlup_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// trackedBody represents response body remembering whether it was read to the end and closed.
+type trackedBody struct {
+	io.Reader
+	drained bool
+	closed  bool
+}
+
+func (tb *trackedBody) Read(p []byte) (int, error) {
+	n, err := tb.Reader.Read(p)
+
+	if err == io.EOF {
+		tb.drained = true
+	}
+
+	return n, err
+}
+
+func (tb *trackedBody) Close() error {
+	tb.closed = true
+	return nil
+}
+
+func Test_json_body_content_type(t *testing.T) {
+	var contentTypes []string
+
+	client := testutil.NewTestLvlClient("token", func(r *http.Request) (*http.Response, error) {
+		contentTypes = append(contentTypes, r.Header.Get("Content-Type"))
+		return testutil.JSON(http.StatusOK, map[string]interface{}{})(r)
+	})
+
+	client.CreatePayment("1.00")
+	client.SetUDPFiltering(7, true)
+	client.WalletBalance()
+
+	assert.Equal(t, []string{"application/json", "application/json", ""}, contentTypes)
+}
+
+func Test_response_body_drained(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		call   func(client *lvlup.LvlClient) error
+	}{
+		{"success without result", http.StatusOK, func(client *lvlup.LvlClient) error {
+			return client.StartVPS(7)
+		}},
+		{"success with result", http.StatusOK, func(client *lvlup.LvlClient) error {
+			_, err := client.ListDDoSAttacks(7)
+			return err
+		}},
+		{"error", http.StatusInternalServerError, func(client *lvlup.LvlClient) error {
+			_, err := client.ListDDoSAttacks(7)
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body := &trackedBody{Reader: strings.NewReader(`{"count": 0, "items": []}` + strings.Repeat(" ", 1024))}
+
+			client := testutil.NewTestLvlClient("token", func(r *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: test.status, Body: body}, nil
+			})
+
+			test.call(client)
+
+			assert.True(t, body.drained, "Body should be drained")
+			assert.True(t, body.closed, "Body should be closed")
+		})
+	}
+}
+
+func Test_api_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.JSON(http.StatusNotFound, map[string]string{"error": "exception not found"}))
+
+	err := client.RemoveUDPFilterException(7, 3)
+
+	var apiErr *lvlup.APIError
+	assert.True(t, errors.As(err, &apiErr), "Error should be APIError")
+	assert.Equal(t, "RemoveUDPFilterException", apiErr.Operation)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, `{"error":"exception not found"}`, apiErr.Message)
+	assert.True(t, errors.Is(err, lvlup.ErrNotFound), "Error should be ErrNotFound")
+
+	client = testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusBadRequest))
+
+	_, err = client.ListServices()
+
+	assert.Equal(t, "ListServices: 400 Bad Request", err.Error())
+	assert.False(t, errors.Is(err, lvlup.ErrNotFound), "Error should not be ErrNotFound")
+}
@@ -0,0 +1,119 @@
+package lvlup
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy represents how failed requests are retried.
+// Requests are retried after transport errors, 429 Too Many Requests and 5xx responses.
+// POST requests are never retried, unless RetryIdempotentPosts is set and they carry
+// Idempotency-Key header. It's only safe if the api deduplicates requests by the key,
+// otherwise a retried CreatePayment may create a second payment.
+type RetryPolicy struct {
+	MaxRetries           int           `json:"maxRetries"`
+	Backoff              time.Duration `json:"backoff"`
+	MaxBackoff           time.Duration `json:"maxBackoff"`
+	RetryIdempotentPosts bool          `json:"retryIdempotentPosts"`
+}
+
+// WithRetryPolicy sets how failed requests are retried. Requests are not retried by default.
+func WithRetryPolicy(policy RetryPolicy) LvlClientOption {
+	return func(lc *LvlClient) {
+		lc.retryPolicy = policy
+	}
+}
+
+// WithRateLimit limits requests made by the client to perSecond, allowing bursts of burst requests.
+// The limit is shared by copies of the client. Non-positive perSecond disables the limit.
+func WithRateLimit(perSecond float64, burst int) LvlClientOption {
+	return func(lc *LvlClient) {
+		if perSecond <= 0 {
+			lc.limiter = nil
+			return
+		}
+
+		lc.limiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+// retryable reports whether the request may be retried.
+func (rp RetryPolicy) retryable(method string, options *requestOptions, response *http.Response, err error) bool {
+	if method == http.MethodPost && (!rp.RetryIdempotentPosts || options.Headers["Idempotency-Key"] == "") {
+		return false
+	}
+
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// delay returns time to wait before the next attempt.
+// Retry-After header of the response is respected if present.
+func (rp RetryPolicy) delay(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	delay := time.Duration(float64(rp.Backoff) * math.Pow(2, float64(attempt)))
+
+	if rp.MaxBackoff > 0 && delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+
+	return delay
+}
+
+// rateLimiter represents a token bucket limiting rate of requests.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// newRateLimiter creates new limiter with full bucket.
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a request can be made or ctx is done.
+// It returns ctx error if ctx is done first.
+func (rl *rateLimiter) wait(ctx context.Context) error {
+	rl.mu.Lock()
+
+	now := time.Now()
+	rl.tokens = math.Min(rl.burst, rl.tokens+float64(now.Sub(rl.last))/float64(rl.interval))
+	rl.last = now
+	rl.tokens--
+
+	var delay time.Duration
+	if rl.tokens < 0 {
+		delay = time.Duration(-rl.tokens * float64(rl.interval))
+	}
+
+	rl.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
@@ -0,0 +1,111 @@
+package lvlup_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyHandler fails first failures requests with status, then responds with wallet balance.
+func flakyHandler(failures int, status int, requests *int) testutil.RoundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		*requests++
+
+		if *requests <= failures {
+			if status == 0 {
+				return nil, errors.New("connection reset")
+			}
+
+			return testutil.HttpError(status)(r)
+		}
+
+		return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: 1})(r)
+	}
+}
+
+func Test_retry_policy(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		failures int
+		requests int
+		ok       bool
+	}{
+		{"server error", http.StatusBadGateway, 2, 3, true},
+		{"too many requests", http.StatusTooManyRequests, 1, 2, true},
+		{"transport error", 0, 1, 2, true},
+		{"client error", http.StatusBadRequest, 1, 1, false},
+		{"retries exhausted", http.StatusInternalServerError, 5, 4, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			client := testutil.NewTestLvlClient("token", flakyHandler(test.failures, test.status, &requests),
+				lvlup.WithRetryPolicy(lvlup.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+			)
+
+			_, err := client.WalletBalance()
+
+			assert.Equal(t, test.ok, err == nil, "Unexpected error %v", err)
+			assert.Equal(t, test.requests, requests)
+		})
+	}
+}
+
+func Test_retry_policy_skips_post_without_idempotency_key(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", flakyHandler(1, http.StatusBadGateway, &requests),
+		lvlup.WithRetryPolicy(lvlup.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+	)
+
+	_, err := client.CreatePayment("1.00")
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 1, requests)
+}
+
+func Test_retry_policy_skips_post_with_idempotency_key(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", flakyHandler(1, http.StatusBadGateway, &requests),
+		lvlup.WithRetryPolicy(lvlup.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond}),
+	)
+
+	_, err := client.CreatePayment("1.00", lvlup.WithIdempotencyKey("order-1"))
+
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, 1, requests)
+}
+
+func Test_retry_policy_retries_idempotent_post_when_enabled(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", flakyHandler(1, http.StatusBadGateway, &requests),
+		lvlup.WithRetryPolicy(lvlup.RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond, RetryIdempotentPosts: true}),
+	)
+
+	_, err := client.CreatePayment("1.00", lvlup.WithIdempotencyKey("order-1"))
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 2, requests)
+}
+
+func Test_rate_limit(t *testing.T) {
+	requests := 0
+	client := testutil.NewTestLvlClient("token", flakyHandler(0, 0, &requests), lvlup.WithRateLimit(50, 1))
+
+	start := time.Now()
+
+	for i := 0; i < 4; i++ {
+		_, err := client.WalletBalance()
+		assert.Nil(t, err, "Error should be nil")
+	}
+
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(50*time.Millisecond))
+	assert.Equal(t, 4, requests)
+}
@@ -0,0 +1,53 @@
+package lvlup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// redacted is printed in place of secret values.
+const redacted = "[REDACTED]"
+
+// Secret represents a sensitive value like api key or password.
+// It's redacted when formatted with any fmt verb or encoded as json,
+// so it doesn't leak into logs. Use Reveal to access the value.
+type Secret string
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String returns redacted value.
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString returns redacted value.
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format writes redacted value regardless of the verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+// MarshalJSON encodes redacted value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// UnmarshalJSON decodes the secret from json string.
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value string
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	*s = Secret(value)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package lvlup_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var formatVerbs = []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d", "%10s", "%-10v", "%T %v"}
+
+func assertNotPrinted(t *testing.T, value interface{}, secret string) {
+	for _, verb := range formatVerbs {
+		formatted := fmt.Sprintf(verb, value)
+		assert.False(t, strings.Contains(formatted, secret), "%s leaked the secret: %s", verb, formatted)
+		assert.False(t, strings.Contains(formatted, fmt.Sprintf("%x", secret)), "%s leaked the secret: %s", verb, formatted)
+	}
+}
+
+func assertNotLeaked(t *testing.T, value interface{}, secret string) {
+	assertNotPrinted(t, value, secret)
+
+	encoded, err := json.Marshal(value)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.False(t, strings.Contains(string(encoded), secret), "json leaked the secret: %s", encoded)
+}
+
+func Test_secret_is_redacted(t *testing.T) {
+	secret := lvlup.Secret("hunter2")
+
+	assertNotLeaked(t, secret, "hunter2")
+	assert.Equal(t, "[REDACTED]", secret.String())
+	assert.Equal(t, "hunter2", secret.Reveal())
+}
+
+func Test_client_api_key_is_redacted(t *testing.T) {
+	client := lvlup.NewLvlClient("super-secret-key", http.DefaultClient)
+
+	assertNotPrinted(t, client, "super-secret-key")
+	assertNotPrinted(t, *client, "super-secret-key")
+	assertNotLeaked(t, lvlup.StaticCredentials("super-secret-key"), "super-secret-key")
+	assert.Equal(t, "super-secret-key", client.ApiKey.Reveal())
+}
+
+func Test_file_credentials_are_redacted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	assert.Nil(t, os.WriteFile(path, []byte("file-secret-key"), 0600))
+
+	credentials := lvlup.NewFileCredentials(path)
+	_, err := credentials.ApiKey()
+
+	assert.Nil(t, err, "Error should be nil")
+	assertNotLeaked(t, credentials, "file-secret-key")
+}
+
+func Test_config_api_key_is_redacted(t *testing.T) {
+	config := lvlup.Config{ApiKey: "config-secret-key"}
+
+	assertNotLeaked(t, config, "config-secret-key")
+}
+
+func Test_proxmo_password_is_redacted(t *testing.T) {
+	handler := testutil.JSON(http.StatusOK, json.RawMessage(`{"password":"proxmo-pass","url":"https://proxmo","username":"user"}`))
+	client := testutil.NewTestLvlClient("token", handler)
+
+	proxmo, err := client.GetProxmoUser(1)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "proxmo-pass", proxmo.Password.Reveal())
+	assertNotLeaked(t, proxmo, "proxmo-pass")
+	assertNotLeaked(t, *proxmo, "proxmo-pass")
+}
@@ -1,23 +1,133 @@
 package lvlup
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Service represents single service from ListServices func result.
+// Id identifies the service in requests. ServiceId is a separate number reported
+// by the api, it's not a ServiceID and is kept as plain int so the two can't be mixed up.
 type Service struct {
-	Id        int    `json:"id"`
-	PlanName  string `json:"planName"`
-	Active    bool   `json:"active"`
-	CreatedAt string `json:"createdAt"`
-	PayedTo   string `json:"payedTo"`
-	Ip        string `json:"ip"`
-	Name      string `json:"name"`
-	NodeId    int    `json:"nodeId"`
-	ServiceId int    `json:"serviceId"`
+	Id        ServiceID `json:"id"`
+	PlanName  string    `json:"planName"`
+	Active    bool      `json:"active"`
+	CreatedAt string    `json:"createdAt"`
+	PayedTo   string    `json:"payedTo"`
+	Ip        string    `json:"ip"`
+	Name      string    `json:"name"`
+	NodeId    int       `json:"nodeId"`
+	ServiceId int       `json:"serviceId"`
+}
+
+// ServiceKind represents kind of a service.
+type ServiceKind int
+
+const (
+	KindUnknown ServiceKind = iota
+	KindVPS
+	KindDomain
+	KindOther
+)
+
+// String returns name of the service kind.
+func (sk ServiceKind) String() string {
+	switch sk {
+	case KindVPS:
+		return "vps"
+	case KindDomain:
+		return "domain"
+	case KindOther:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
+
+// Kind allows to check what kind of service it is.
+// The kind is derived from the plan name, as the api does not report it directly.
+func (s Service) Kind() ServiceKind {
+	plan := strings.ToLower(s.PlanName)
+
+	switch {
+	case plan == "":
+		return KindUnknown
+	case strings.Contains(plan, "vps"):
+		return KindVPS
+	case strings.Contains(plan, "domain"), strings.Contains(plan, "domen"):
+		return KindDomain
+	default:
+		return KindOther
+	}
+}
+
+// PayedToTime allows to get the time until which the service is payed.
+func (s Service) PayedToTime() (time.Time, error) {
+	return parseTime(s.PayedTo)
+}
+
+// CreatedAtTime allows to get the time at which the service was created.
+func (s Service) CreatedAtTime() (time.Time, error) {
+	return parseTime(s.CreatedAt)
+}
+
+// VPSService represents a service which is a VPS.
+type VPSService struct {
+	Id       VPSID
+	Name     string
+	PlanName string
+	Active   bool
+	PayedTo  time.Time
+	Ip       string
+	NodeId   int
+}
+
+// AsVPS allows to access the service as a VPS.
+// It returns false if the service is not a VPS.
+func (s Service) AsVPS() (*VPSService, bool) {
+	if s.Kind() != KindVPS {
+		return nil, false
+	}
+
+	payedTo, _ := s.PayedToTime()
+
+	return &VPSService{
+		Id:       s.Id.VPS(),
+		Name:     s.Name,
+		PlanName: s.PlanName,
+		Active:   s.Active,
+		PayedTo:  payedTo,
+		Ip:       s.Ip,
+		NodeId:   s.NodeId,
+	}, true
+}
+
+// DomainService represents a service which is a domain.
+type DomainService struct {
+	Id       ServiceID
+	Domain   string
+	PlanName string
+	Active   bool
+	PayedTo  time.Time
+}
+
+// AsDomain allows to access the service as a domain.
+// It returns false if the service is not a domain.
+func (s Service) AsDomain() (*DomainService, bool) {
+	if s.Kind() != KindDomain {
+		return nil, false
+	}
+
+	payedTo, _ := s.PayedToTime()
+
+	return &DomainService{
+		Id:       s.Id,
+		Domain:   s.Name,
+		PlanName: s.PlanName,
+		Active:   s.Active,
+		PayedTo:  payedTo,
+	}, true
 }
 
 // ListServices represents result of ListServices func.
@@ -25,31 +135,83 @@ type ListServicesResult struct {
 	Services []Service
 }
 
-// ListServices allows to list all services like VPS or domains.
-// It returns request result or any errors encountered.
-func (lc LvlClient) ListServices() (*ListServicesResult, error) {
-	response, err := lc.get(
-		"/services",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
+// ListServicesOptions represents filters applied to services returned by ListServices func.
+type ListServicesOptions struct {
+	Filters []func(Service) bool
+}
+
+// ListServicesOption represents functional option for ListServices func.
+type ListServicesOption func(*ListServicesOptions)
+
+// WithKind allows to list only services of specified kind.
+func WithKind(kind ServiceKind) ListServicesOption {
+	return func(lso *ListServicesOptions) {
+		lso.Filters = append(lso.Filters, func(s Service) bool {
+			return s.Kind() == kind
+		})
+	}
+}
+
+// WithActive allows to list only active or only inactive services.
+func WithActive(active bool) ListServicesOption {
+	return func(lso *ListServicesOptions) {
+		lso.Filters = append(lso.Filters, func(s Service) bool {
+			return s.Active == active
+		})
 	}
+}
+
+// WithExpiringWithin allows to list only services which are payed to a moment within specified duration from now.
+func WithExpiringWithin(d time.Duration) ListServicesOption {
+	return func(lso *ListServicesOptions) {
+		lso.Filters = append(lso.Filters, func(s Service) bool {
+			payedTo, err := s.PayedToTime()
+
+			if err != nil {
+				return false
+			}
 
-	defer response.Body.Close()
+			left := time.Until(payedTo)
+			return left >= 0 && left <= d
+		})
+	}
+}
+
+// ListServices allows to list all services like VPS or domains.
+// Provided options filter returned services.
+// It returns request result or any errors encountered.
+func (lc LvlClient) ListServices(opts ...ListServicesOption) (*ListServicesResult, error) {
+	options := &ListServicesOptions{}
 
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(http.StatusText(response.StatusCode))
+	for _, opt := range opts {
+		opt(options)
 	}
 
 	var result ListServicesResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+	if err := lc.call(
+		operation{Name: "ListServices", Method: http.MethodGet, Path: "/services"},
+		nil, &result,
+	); err != nil {
 		return nil, err
 	}
 
+	if len(options.Filters) > 0 {
+		services := make([]Service, 0, len(result.Services))
+
+	next:
+		for _, service := range result.Services {
+			for _, filter := range options.Filters {
+				if !filter(service) {
+					continue next
+				}
+			}
+
+			services = append(services, service)
+		}
+
+		result.Services = services
+	}
+
 	return &result, nil
 }
 
@@ -68,28 +230,8 @@ type ListDDoSAttacksResult struct {
 }
 
 // ListDDoSAttacks allows to access list of DDoS attacks for specific VPS.
-func (lc LvlClient) ListDDoSAttacks(vpsId string) (*ListDDoSAttacksResult, error) {
-	response, err := lc.get(
-		"/services/vps/"+vpsId+"/attacks",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var result ListDDoSAttacksResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+func (lc LvlClient) ListDDoSAttacks(vpsId VPSID) (*ListDDoSAttacksResult, error) {
+	return lc.VPS(vpsId).Attacks()
 }
 
 // GetUDPFilterResult represents result of GetUDPFilter func.
@@ -99,30 +241,8 @@ type GetUDPFilterResult struct {
 }
 
 // GetUDPFilter allows to check UDP filtering status for specified VPS.
-func (lc LvlClient) GetUDPFilter(vpsId string) (*GetUDPFilterResult, error) {
-	response, err := lc.get(
-		"/services/vps/"+vpsId+"/filtering",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var result GetUDPFilterResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+func (lc LvlClient) GetUDPFilter(vpsId VPSID) (*GetUDPFilterResult, error) {
+	return lc.VPS(vpsId).Filter().Get()
 }
 
 // SetUDPFilteringOptions represents available options for SetUDPFiltering func.
@@ -137,195 +257,54 @@ type SetUDPFilteringResult struct {
 }
 
 // SetUDPFiltering allows to switch UDP filtering status for specified VPS on and off.
-func (lc LvlClient) SetUDPFiltering(vpsId string, filteringEnabled bool) (*SetUDPFilteringResult, error) {
-	options := SetUDPFilteringOptions{
-		FilteringEnabled: filteringEnabled,
-	}
-
-	payload, err := json.Marshal(options)
-
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := lc.put(
-		"/services/vps/"+vpsId+"/filtering",
-		withBody(payload),
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var result SetUDPFilteringResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+func (lc LvlClient) SetUDPFiltering(vpsId VPSID, filteringEnabled bool) (*SetUDPFilteringResult, error) {
+	return lc.VPS(vpsId).Filter().Set(filteringEnabled)
 }
 
 // UDPFilterExceptionPorts represents options for UDP filter exception ports.
 type UDPFilterExceptionPorts struct {
-	From int `json:"form"`
+	From int `json:"from"`
 	To   int `json:"to"`
 }
 
 // UDPFilterWhitelistException represents single exception.
 type UDPFilterException struct {
-	Id       int                       `json:"id"`
+	Id       FilterExceptionID         `json:"id"`
 	Ports    []UDPFilterExceptionPorts `json:"ports"`
 	Protocol string                    `json:"protocol"`
 	State    string                    `json:"state"`
 }
 
 // ListUDPFilterExceptions allows to list all exceptions for UDP filter.
-func (lc LvlClient) ListUDPFilterExceptions(vpsId string) ([]UDPFilterException, error) {
-	response, err := lc.get(
-		"/services/vps/"+vpsId+"/filtering/whitelist",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var result []UDPFilterException
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return result, nil
+func (lc LvlClient) ListUDPFilterExceptions(vpsId VPSID) ([]UDPFilterException, error) {
+	return lc.VPS(vpsId).Filter().Exceptions()
 }
 
 // AddUDPFilterException allows to add exception for UDP filter.
-func (lc LvlClient) AddUDPFilterException(vpsId string, exception *UDPFilterException) error {
-	payload, err := json.Marshal(exception)
-
-	if err != nil {
-		return err
-	}
-
-	response, err := lc.post(
-		"/services/vps/"+vpsId+"/filtering/whitelist",
-		withBody(payload),
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf(response.Status)
-	}
-
-	return nil
+func (lc LvlClient) AddUDPFilterException(vpsId VPSID, exception *UDPFilterException) error {
+	return lc.VPS(vpsId).Filter().AddException(exception)
 }
 
 // RemoveUDPFilterException allows to remove exception for UDP filter.
-func (lc LvlClient) RemoveUDPFilterException(vpsId string, exceptionId string) error {
-	response, err := lc.delete(
-		"/services/vps/"+exceptionId+"/filtering/whitelist/"+exceptionId,
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		message, err := io.ReadAll(response.Body)
-
-		if err != nil {
-			return err
-		}
-
-		return fmt.Errorf("status: %v, message: %s", response.Status, message)
-	}
-
-	return nil
+func (lc LvlClient) RemoveUDPFilterException(vpsId VPSID, exceptionId FilterExceptionID) error {
+	return lc.VPS(vpsId).Filter().RemoveException(exceptionId)
 }
 
 // ProxmoUser represents proxmo user.
 type ProxmoUser struct {
-	Password string `json:"password"`
+	Password Secret `json:"password"`
 	Url      string `json:"url"`
 	Username string `json:"username"`
 }
 
 // GetProxmoUser allows to create new proxmo user, or reset password if already exists.
-func (lc LvlClient) GetProxmoUser(vpsId string) (*ProxmoUser, error) {
-	response, err := lc.post(
-		"/services/vps/"+vpsId+"/proxmo",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var proxmo ProxmoUser
-	if err := json.NewDecoder(response.Body).Decode(&proxmo); err != nil {
-		return nil, err
-	}
-
-	return &proxmo, nil
+func (lc LvlClient) GetProxmoUser(vpsId VPSID) (*ProxmoUser, error) {
+	return lc.VPS(vpsId).Proxmo()
 }
 
 // StartVps allows to start specified VPS server.
-func (lc LvlClient) StartVPS(vpsId string) error {
-	response, err := lc.post(
-		"/services/vps/"+vpsId+"/start",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf(response.Status)
-	}
-
-	return nil
+func (lc LvlClient) StartVPS(vpsId VPSID) error {
+	return lc.VPS(vpsId).Start()
 }
 
 // GetVPSStateResult represents result of GetVPSState.
@@ -335,50 +314,11 @@ type GetVPSStateResult struct {
 }
 
 // GetVPSState allows to get specified VPS state.
-func (lc LvlClient) GetVPSState(vpsId string) (*GetVPSStateResult, error) {
-	response, err := lc.get(
-		"/services/vps/"+vpsId+"/state",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf(response.Status)
-	}
-
-	var result GetVPSStateResult
-	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+func (lc LvlClient) GetVPSState(vpsId VPSID) (*GetVPSStateResult, error) {
+	return lc.VPS(vpsId).State()
 }
 
 // StopVPS allows to stop specified VPS.
-func (lc LvlClient) StopVPS(vpsId string) error {
-	response, err := lc.post(
-		"/services/vps/"+vpsId+"/stop",
-		withHeaders(map[string]string{
-			"Authorization": "Bearer " + lc.ApiKey,
-		}),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return fmt.Errorf(response.Status)
-	}
-
-	return nil
+func (lc LvlClient) StopVPS(vpsId VPSID) error {
+	return lc.VPS(vpsId).Stop()
 }
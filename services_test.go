@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/senicko/lvlup"
 	"github.com/senicko/lvlup/internal/testutil"
@@ -54,9 +55,9 @@ func Test_list_all_services_server_error(t *testing.T) {
 }
 
 func Test_list_DDoS_attacks(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/attacks"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/attacks"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -89,15 +90,15 @@ func Test_list_DDoS_attacks(t *testing.T) {
 func Test_list_DDoS_attacks_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusBadRequest))
 
-	_, err := client.ListDDoSAttacks("1")
+	_, err := client.ListDDoSAttacks(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_get_UDP_filter(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/filtering"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/filtering"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -130,15 +131,15 @@ func Test_get_UDP_filter(t *testing.T) {
 func Test_get_UDP_filter_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	_, err := client.GetUDPFilter("1")
+	_, err := client.GetUDPFilter(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_set_UDP_filtering(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/filtering"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/filtering"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -180,15 +181,15 @@ func Test_set_UDP_filtering(t *testing.T) {
 func Test_set_UDP_filtering_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	_, err := client.SetUDPFiltering("1", true)
+	_, err := client.SetUDPFiltering(1, true)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_list_UDP_filter_exceptions(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/filtering/whitelist"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/filtering/whitelist"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -221,15 +222,15 @@ func Test_list_UDP_filter_exceptions(t *testing.T) {
 func Test_list_UDP_filter_exceptions_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	_, err := client.ListUDPFilterExceptions("1")
+	_, err := client.ListUDPFilterExceptions(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_add_UDP_filter_exception(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/filtering/whitelist"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/filtering/whitelist"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -256,16 +257,16 @@ func Test_add_UDP_filter_exception(t *testing.T) {
 func Test_add_UDP_filter_exception_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	err := client.AddUDPFilterException("1", &lvlup.UDPFilterException{})
+	err := client.AddUDPFilterException(1, &lvlup.UDPFilterException{})
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_remove_UDP_filter_exception(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	exceptionId := "1"
-	expectedPath := "/v4/services/vps/" + vpsId + "/filtering/whitelist/" + exceptionId
+	exceptionId := lvlup.FilterExceptionID(1)
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/filtering/whitelist/" + exceptionId.String()
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -292,15 +293,15 @@ func Test_remove_UDP_filter_exception(t *testing.T) {
 func Test_remove_UDP_filter_exception_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	err := client.RemoveUDPFilterException("1", "1")
+	err := client.RemoveUDPFilterException(1, 1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_get_proxmo_user(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/proxmo"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/proxmo"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -333,15 +334,15 @@ func Test_get_proxmo_user(t *testing.T) {
 func Test_get_proxmo_user_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	_, err := client.GetProxmoUser("1")
+	_, err := client.GetProxmoUser(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_start_VPS(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/start"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/start"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -368,15 +369,15 @@ func Test_start_VPS(t *testing.T) {
 func Test_start_VPS_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	err := client.StartVPS("1")
+	err := client.StartVPS(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_get_VPS_state(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/state"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/state"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -409,15 +410,15 @@ func Test_get_VPS_state(t *testing.T) {
 func Test_get_VPS_state_server_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	_, err := client.GetVPSState("1")
+	_, err := client.GetVPSState(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
 
 func Test_stop_VPS(t *testing.T) {
-	vpsId := "1"
+	vpsId := lvlup.VPSID(1)
 	apiKey := "token"
-	expectedPath := "/v4/services/vps/" + vpsId + "/stop"
+	expectedPath := "/v4/services/vps/" + vpsId.String() + "/stop"
 	expectedApiKey := "Bearer " + apiKey
 
 	handler := func(r *http.Request) (*http.Response, error) {
@@ -444,7 +445,56 @@ func Test_stop_VPS(t *testing.T) {
 func Test_stop_VPS_error(t *testing.T) {
 	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
 
-	err := client.StopVPS("1")
+	err := client.StopVPS(1)
 
 	assert.NotNil(t, err, "Error should not be nil")
 }
+
+func Test_service_kind(t *testing.T) {
+	assert.Equal(t, lvlup.KindVPS, lvlup.Service{PlanName: "VPS Start"}.Kind())
+	assert.Equal(t, lvlup.KindDomain, lvlup.Service{PlanName: "Domena .pl"}.Kind())
+	assert.Equal(t, lvlup.KindOther, lvlup.Service{PlanName: "Hosting"}.Kind())
+	assert.Equal(t, lvlup.KindUnknown, lvlup.Service{}.Kind())
+}
+
+func Test_service_typed_accessors(t *testing.T) {
+	service := lvlup.Service{Id: 1, PlanName: "VPS Start", Ip: "10.0.0.1", PayedTo: "2021-08-01T00:00:00Z"}
+
+	vps, ok := service.AsVPS()
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1", vps.Ip)
+	assert.Equal(t, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), vps.PayedTo)
+
+	_, ok = service.AsDomain()
+	assert.False(t, ok)
+
+	domain, ok := lvlup.Service{PlanName: "Domain", Name: "example.pl"}.AsDomain()
+	assert.True(t, ok)
+	assert.Equal(t, "example.pl", domain.Domain)
+}
+
+func Test_list_services_with_filters(t *testing.T) {
+	soon := time.Now().Add(3 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	later := time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	handler := testutil.JSON(http.StatusOK, lvlup.ListServicesResult{
+		Services: []lvlup.Service{
+			{Id: 1, PlanName: "VPS", Active: true, PayedTo: soon},
+			{Id: 2, PlanName: "VPS", Active: false, PayedTo: soon},
+			{Id: 3, PlanName: "VPS", Active: true, PayedTo: later},
+			{Id: 4, PlanName: "Domain", Active: true, PayedTo: soon},
+		},
+	})
+
+	client := testutil.NewTestLvlClient("token", handler)
+
+	result, err := client.ListServices(
+		lvlup.WithKind(lvlup.KindVPS),
+		lvlup.WithActive(true),
+		lvlup.WithExpiringWithin(7*24*time.Hour),
+	)
+
+	assert.Nil(t, err, "Error should be nil")
+	assert.Len(t, result.Services, 1)
+	assert.Equal(t, lvlup.ServiceID(1), result.Services[0].Id)
+}
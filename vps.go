@@ -0,0 +1,160 @@
+package lvlup
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// buildPath joins escaped segments into a request path.
+func buildPath(segments ...string) string {
+	escaped := make([]string, len(segments))
+
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	return "/" + strings.Join(escaped, "/")
+}
+
+// VPSClient represents a client bound to a single VPS.
+type VPSClient struct {
+	client *LvlClient
+	id     VPSID
+}
+
+// VPS allows to get a client for VPS with specified id.
+func (lc *LvlClient) VPS(vpsId VPSID) *VPSClient {
+	return &VPSClient{
+		client: lc,
+		id:     vpsId,
+	}
+}
+
+// Id returns id of the VPS.
+func (vc *VPSClient) Id() VPSID {
+	return vc.id
+}
+
+// path builds path of a VPS endpoint.
+// It returns an error if the VPS id is invalid.
+func (vc *VPSClient) path(segments ...string) (string, error) {
+	if vc.id <= 0 {
+		return "", fmt.Errorf("%w: vps id %d", ErrInvalidID, vc.id)
+	}
+
+	return buildPath(append([]string{"services", "vps", vc.id.String()}, segments...)...), nil
+}
+
+// Start allows to start the VPS.
+func (vc *VPSClient) Start() error {
+	return vc.call("StartVPS", http.MethodPost, nil, nil, "start")
+}
+
+// Stop allows to stop the VPS.
+func (vc *VPSClient) Stop() error {
+	return vc.call("StopVPS", http.MethodPost, nil, nil, "stop")
+}
+
+// State allows to get the VPS state.
+func (vc *VPSClient) State() (*GetVPSStateResult, error) {
+	var result GetVPSStateResult
+	if err := vc.call("GetVPSState", http.MethodGet, nil, &result, "state"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Attacks allows to list DDoS attacks on the VPS.
+func (vc *VPSClient) Attacks() (*ListDDoSAttacksResult, error) {
+	var result ListDDoSAttacksResult
+	if err := vc.call("ListDDoSAttacks", http.MethodGet, nil, &result, "attacks"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Proxmo allows to create new proxmo user for the VPS, or reset password if already exists.
+func (vc *VPSClient) Proxmo() (*ProxmoUser, error) {
+	var proxmo ProxmoUser
+	if err := vc.call("GetProxmoUser", http.MethodPost, nil, &proxmo, "proxmo"); err != nil {
+		return nil, err
+	}
+
+	return &proxmo, nil
+}
+
+// call executes operation of the VPS endpoint at path segments.
+// It returns an error if the VPS id is invalid or the request fails.
+func (vc *VPSClient) call(name string, method string, body interface{}, result interface{}, segments ...string) error {
+	path, err := vc.path(segments...)
+
+	if err != nil {
+		return err
+	}
+
+	return vc.client.call(operation{Name: name, Method: method, Path: path}, body, result)
+}
+
+// Filter allows to get a client for UDP filter of the VPS.
+func (vc *VPSClient) Filter() *VPSFilterClient {
+	return &VPSFilterClient{
+		vps: vc,
+	}
+}
+
+// VPSFilterClient represents a client bound to UDP filter of a single VPS.
+type VPSFilterClient struct {
+	vps *VPSClient
+}
+
+// Get allows to check UDP filtering status.
+func (fc *VPSFilterClient) Get() (*GetUDPFilterResult, error) {
+	var result GetUDPFilterResult
+	if err := fc.vps.call("GetUDPFilter", http.MethodGet, nil, &result, "filtering"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Set allows to switch UDP filtering on and off.
+func (fc *VPSFilterClient) Set(filteringEnabled bool) (*SetUDPFilteringResult, error) {
+	options := SetUDPFilteringOptions{
+		FilteringEnabled: filteringEnabled,
+	}
+
+	var result SetUDPFilteringResult
+	if err := fc.vps.call("SetUDPFiltering", http.MethodPut, options, &result, "filtering"); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Exceptions allows to list all exceptions for UDP filter.
+func (fc *VPSFilterClient) Exceptions() ([]UDPFilterException, error) {
+	var result []UDPFilterException
+	if err := fc.vps.call("ListUDPFilterExceptions", http.MethodGet, nil, &result, "filtering", "whitelist"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AddException allows to add exception for UDP filter.
+func (fc *VPSFilterClient) AddException(exception *UDPFilterException) error {
+	return fc.vps.call("AddUDPFilterException", http.MethodPost, exception, nil, "filtering", "whitelist")
+}
+
+// RemoveException allows to remove exception for UDP filter.
+func (fc *VPSFilterClient) RemoveException(exceptionId FilterExceptionID) error {
+	if exceptionId <= 0 {
+		return fmt.Errorf("%w: exception id %d", ErrInvalidID, exceptionId)
+	}
+
+	return fc.vps.call("RemoveUDPFilterException", http.MethodDelete, nil, nil, "filtering", "whitelist", exceptionId.String())
+}
@@ -0,0 +1,105 @@
+package lvlup_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VPS_client_paths(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		call   func(vps *lvlup.VPSClient) error
+	}{
+		{"start", http.MethodPost, "/v4/services/vps/7/start", func(vps *lvlup.VPSClient) error {
+			return vps.Start()
+		}},
+		{"stop", http.MethodPost, "/v4/services/vps/7/stop", func(vps *lvlup.VPSClient) error {
+			return vps.Stop()
+		}},
+		{"state", http.MethodGet, "/v4/services/vps/7/state", func(vps *lvlup.VPSClient) error {
+			_, err := vps.State()
+			return err
+		}},
+		{"attacks", http.MethodGet, "/v4/services/vps/7/attacks", func(vps *lvlup.VPSClient) error {
+			_, err := vps.Attacks()
+			return err
+		}},
+		{"proxmo", http.MethodPost, "/v4/services/vps/7/proxmo", func(vps *lvlup.VPSClient) error {
+			_, err := vps.Proxmo()
+			return err
+		}},
+		{"filter get", http.MethodGet, "/v4/services/vps/7/filtering", func(vps *lvlup.VPSClient) error {
+			_, err := vps.Filter().Get()
+			return err
+		}},
+		{"filter set", http.MethodPut, "/v4/services/vps/7/filtering", func(vps *lvlup.VPSClient) error {
+			_, err := vps.Filter().Set(true)
+			return err
+		}},
+		{"filter exceptions", http.MethodGet, "/v4/services/vps/7/filtering/whitelist", func(vps *lvlup.VPSClient) error {
+			_, err := vps.Filter().Exceptions()
+			return err
+		}},
+		{"filter add exception", http.MethodPost, "/v4/services/vps/7/filtering/whitelist", func(vps *lvlup.VPSClient) error {
+			return vps.Filter().AddException(&lvlup.UDPFilterException{})
+		}},
+		{"filter remove exception", http.MethodDelete, "/v4/services/vps/7/filtering/whitelist/3", func(vps *lvlup.VPSClient) error {
+			return vps.Filter().RemoveException(3)
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var method, path string
+
+			handler := func(r *http.Request) (*http.Response, error) {
+				method, path = r.Method, r.URL.Path
+
+				if r.Header.Get("Authorization") != "Bearer token" {
+					return testutil.HttpError(http.StatusUnauthorized)(r)
+				}
+
+				body := []byte("{}")
+				if r.URL.Path == "/v4/services/vps/7/filtering/whitelist" && r.Method == http.MethodGet {
+					body = []byte("[]")
+				}
+
+				return testutil.JSON(http.StatusOK, json.RawMessage(body))(r)
+			}
+
+			vps := testutil.NewTestLvlClient("token", handler).VPS(7)
+
+			err := test.call(vps)
+
+			assert.Nil(t, err, "Error should be nil")
+			assert.Equal(t, test.method, method)
+			assert.Equal(t, test.path, path)
+		})
+	}
+}
+
+func Test_VPS_client_invalid_id(t *testing.T) {
+	handler := func(r *http.Request) (*http.Response, error) {
+		t.Errorf("Request made to %v with invalid id", r.URL.Path)
+		return testutil.HttpError(http.StatusOK)(r)
+	}
+
+	client := testutil.NewTestLvlClient("token", handler)
+
+	for _, id := range []lvlup.VPSID{0, -1} {
+		err := client.VPS(id).Start()
+		assert.True(t, errors.Is(err, lvlup.ErrInvalidID), "Error should be ErrInvalidID for %d", id)
+	}
+
+	err := client.VPS(1).Filter().RemoveException(0)
+	assert.True(t, errors.Is(err, lvlup.ErrInvalidID), "Error should be ErrInvalidID")
+}
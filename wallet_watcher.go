@@ -0,0 +1,281 @@
+package lvlup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WalletEventKind represents kind of an event emitted by WalletWatcher.
+type WalletEventKind int
+
+const (
+	WalletBalanceChanged WalletEventKind = iota
+	WalletBelowThreshold
+	WalletUnexpectedDrop
+)
+
+// String returns name of the event kind.
+func (wek WalletEventKind) String() string {
+	switch wek {
+	case WalletBalanceChanged:
+		return "balance changed"
+	case WalletBelowThreshold:
+		return "below threshold"
+	case WalletUnexpectedDrop:
+		return "unexpected drop"
+	default:
+		return "unknown"
+	}
+}
+
+// WalletEvent represents a change of wallet balance noticed by WalletWatcher.
+// Balances are in grosz, as returned in WalletBalanceResult.BalancePlnInt.
+type WalletEvent struct {
+	Kind      WalletEventKind
+	Previous  int
+	Current   int
+	Threshold int
+	Payments  []ListPaymentsResultItem
+}
+
+// WalletHandler describes receiver of events emitted by WalletWatcher.
+type WalletHandler interface {
+	HandleWalletEvent(event WalletEvent)
+}
+
+// WalletHandlerFunc allows to use a func as WalletHandler.
+type WalletHandlerFunc func(event WalletEvent)
+
+// HandleWalletEvent calls f(event).
+func (f WalletHandlerFunc) HandleWalletEvent(event WalletEvent) {
+	f(event)
+}
+
+// WalletWatcherOptions represents available options for WalletWatcher.
+type WalletWatcherOptions struct {
+	Interval          time.Duration
+	Thresholds        []int
+	MaxDrop           int
+	CorrelatePayments bool
+	ErrorHandler      func(error)
+}
+
+// WalletWatcherOption represents a functional option for WalletWatcher.
+type WalletWatcherOption func(*WalletWatcherOptions)
+
+// WithWalletInterval sets how often the balance is polled.
+// Non-positive intervals are replaced with the default one.
+func WithWalletInterval(interval time.Duration) WalletWatcherOption {
+	return func(wwo *WalletWatcherOptions) {
+		wwo.Interval = interval
+	}
+}
+
+// WithBalanceThresholds sets balances in grosz below which WalletBelowThreshold events are emitted.
+func WithBalanceThresholds(thresholds ...int) WalletWatcherOption {
+	return func(wwo *WalletWatcherOptions) {
+		wwo.Thresholds = thresholds
+	}
+}
+
+// WithMaxDrop sets the biggest drop in grosz between two polls that is still expected.
+// Bigger drops emit WalletUnexpectedDrop events. By default every drop is unexpected.
+func WithMaxDrop(maxDrop int) WalletWatcherOption {
+	return func(wwo *WalletWatcherOptions) {
+		wwo.MaxDrop = maxDrop
+	}
+}
+
+// WithPaymentsCorrelation attaches payments made since the previous poll to emitted events.
+func WithPaymentsCorrelation() WalletWatcherOption {
+	return func(wwo *WalletWatcherOptions) {
+		wwo.CorrelatePayments = true
+	}
+}
+
+// WithWalletErrorHandler sets func called with errors encountered by Run.
+func WithWalletErrorHandler(handler func(error)) WalletWatcherOption {
+	return func(wwo *WalletWatcherOptions) {
+		wwo.ErrorHandler = handler
+	}
+}
+
+// WalletWatcher periodically polls wallet balance and emits events about its changes.
+type WalletWatcher struct {
+	client  *LvlClient
+	handler WalletHandler
+	options *WalletWatcherOptions
+
+	mu            sync.Mutex
+	polled        bool
+	balance       int
+	lastPaymentId PaymentItemID
+	below         map[int]bool
+}
+
+// defaultWalletInterval is how often the balance is polled if no valid interval is set.
+const defaultWalletInterval = time.Minute
+
+// walletPaymentsPageSize is the number of payments fetched per request when correlating payments.
+const walletPaymentsPageSize = 100
+
+// NewWalletWatcher creates new watcher sending events to the handler.
+// By default the balance is polled every minute.
+func NewWalletWatcher(client *LvlClient, handler WalletHandler, opts ...WalletWatcherOption) *WalletWatcher {
+	options := &WalletWatcherOptions{
+		Interval: defaultWalletInterval,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = defaultWalletInterval
+	}
+
+	return &WalletWatcher{
+		client:  client,
+		handler: handler,
+		options: options,
+		below:   map[int]bool{},
+	}
+}
+
+// Run polls the balance every interval until ctx is done.
+// It returns ctx error.
+func (ww *WalletWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(ww.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := ww.Poll(); err != nil && ww.options.ErrorHandler != nil {
+			ww.options.ErrorHandler(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the balance once and emits events for noticed changes.
+// The first poll only emits WalletBelowThreshold events.
+// It returns any errors encountered.
+func (ww *WalletWatcher) Poll() error {
+	ww.mu.Lock()
+	defer ww.mu.Unlock()
+
+	wallet, err := ww.client.WalletBalance()
+
+	if err != nil {
+		return err
+	}
+
+	payments, err := ww.newPayments()
+
+	if err != nil {
+		return err
+	}
+
+	previous, current := ww.balance, wallet.BalancePlnInt
+	first := !ww.polled
+
+	ww.polled = true
+	ww.balance = current
+
+	event := WalletEvent{
+		Previous: previous,
+		Current:  current,
+		Payments: payments,
+	}
+
+	if !first && current != previous {
+		event.Kind = WalletBalanceChanged
+		ww.handler.HandleWalletEvent(event)
+
+		if previous-current > ww.options.MaxDrop {
+			event.Kind = WalletUnexpectedDrop
+			ww.handler.HandleWalletEvent(event)
+		}
+	}
+
+	for _, threshold := range ww.options.Thresholds {
+		if current >= threshold {
+			delete(ww.below, threshold)
+			continue
+		}
+
+		if ww.below[threshold] {
+			continue
+		}
+
+		ww.below[threshold] = true
+
+		event.Kind = WalletBelowThreshold
+		event.Threshold = threshold
+		ww.handler.HandleWalletEvent(event)
+	}
+
+	return nil
+}
+
+// newPayments lists payments made after the last seen one, page by page until all are fetched.
+// The last seen payment moves only when every page was fetched, so payments of a failed
+// poll are returned by the next one. On the first call it only remembers the newest payment.
+func (ww *WalletWatcher) newPayments() ([]ListPaymentsResultItem, error) {
+	if !ww.options.CorrelatePayments {
+		return nil, nil
+	}
+
+	if !ww.polled {
+		result, err := ww.client.ListPayments(WithLimit(1))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range result.Items {
+			if item.Id > ww.lastPaymentId {
+				ww.lastPaymentId = item.Id
+			}
+		}
+
+		return nil, nil
+	}
+
+	var payments []ListPaymentsResultItem
+	cursor := ww.lastPaymentId
+
+	for {
+		page, err := ww.client.ListPayments(WithAfterId(cursor), WithLimit(walletPaymentsPageSize))
+
+		if err != nil {
+			return nil, err
+		}
+
+		items := page.Items
+		sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+
+		progressed := false
+
+		for _, item := range items {
+			if item.Id <= cursor {
+				continue
+			}
+
+			payments = append(payments, item)
+			cursor = item.Id
+			progressed = true
+		}
+
+		if !progressed || len(page.Items) < walletPaymentsPageSize {
+			ww.lastPaymentId = cursor
+			return payments, nil
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package lvlup_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/senicko/lvlup"
+	"github.com/senicko/lvlup/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_wallet_watcher_events(t *testing.T) {
+	balance := 5000
+
+	handler := func(r *http.Request) (*http.Response, error) {
+		return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: balance})(r)
+	}
+
+	var events []lvlup.WalletEvent
+	watcher := lvlup.NewWalletWatcher(
+		testutil.NewTestLvlClient("token", handler),
+		lvlup.WalletHandlerFunc(func(event lvlup.WalletEvent) {
+			events = append(events, event)
+		}),
+		lvlup.WithBalanceThresholds(1000, 3000),
+		lvlup.WithMaxDrop(500),
+	)
+
+	assert.Nil(t, watcher.Poll())
+	assert.Empty(t, events, "First poll above thresholds should not emit events")
+
+	balance = 4700
+	assert.Nil(t, watcher.Poll())
+	assert.Len(t, events, 1)
+	assert.Equal(t, lvlup.WalletBalanceChanged, events[0].Kind)
+	assert.Equal(t, 5000, events[0].Previous)
+	assert.Equal(t, 4700, events[0].Current)
+
+	balance = 2000
+	assert.Nil(t, watcher.Poll())
+	assert.Len(t, events, 4)
+	assert.Equal(t, lvlup.WalletBalanceChanged, events[1].Kind)
+	assert.Equal(t, lvlup.WalletUnexpectedDrop, events[2].Kind)
+	assert.Equal(t, lvlup.WalletBelowThreshold, events[3].Kind)
+	assert.Equal(t, 3000, events[3].Threshold)
+
+	assert.Nil(t, watcher.Poll())
+	assert.Len(t, events, 4, "Unchanged balance should not emit events")
+}
+
+func Test_wallet_watcher_correlates_payments(t *testing.T) {
+	balance := 1000
+	var afterIds []string
+
+	handler := testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/wallet": func(r *http.Request) (*http.Response, error) {
+			return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: balance})(r)
+		},
+		"/v4/payments": func(r *http.Request) (*http.Response, error) {
+			afterId := r.URL.Query().Get("afterId")
+			afterIds = append(afterIds, afterId)
+
+			if afterId == "" {
+				return testutil.JSON(http.StatusOK, lvlup.ListPaymentsResult{
+					Count: 1,
+					Items: []lvlup.ListPaymentsResultItem{{Id: 10}},
+				})(r)
+			}
+
+			return testutil.JSON(http.StatusOK, lvlup.ListPaymentsResult{
+				Count: 2,
+				Items: []lvlup.ListPaymentsResultItem{{Id: 11}, {Id: 12}},
+			})(r)
+		},
+	})
+
+	var events []lvlup.WalletEvent
+	watcher := lvlup.NewWalletWatcher(
+		testutil.NewTestLvlClient("token", handler),
+		lvlup.WalletHandlerFunc(func(event lvlup.WalletEvent) {
+			events = append(events, event)
+		}),
+		lvlup.WithPaymentsCorrelation(),
+	)
+
+	assert.Nil(t, watcher.Poll())
+
+	balance = 3000
+	assert.Nil(t, watcher.Poll())
+	assert.Nil(t, watcher.Poll())
+
+	assert.Equal(t, []string{"", "10", "12"}, afterIds)
+	assert.Len(t, events, 1)
+	assert.Len(t, events[0].Payments, 2)
+}
+
+func Test_wallet_watcher_server_error(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	watcher := lvlup.NewWalletWatcher(client, lvlup.WalletHandlerFunc(func(lvlup.WalletEvent) {}))
+
+	assert.NotNil(t, watcher.Poll(), "Error should not be nil")
+}
+
+func Test_wallet_watcher_correlates_all_pages(t *testing.T) {
+	balance := 1000
+	items := []lvlup.ListPaymentsResultItem{{Id: 1}}
+
+	handler := testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/wallet": func(r *http.Request) (*http.Response, error) {
+			return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: balance})(r)
+		},
+		"/v4/payments": func(r *http.Request) (*http.Response, error) {
+			return testutil.PaymentsPager(items)(r)
+		},
+	})
+
+	var events []lvlup.WalletEvent
+	watcher := lvlup.NewWalletWatcher(
+		testutil.NewTestLvlClient("token", handler),
+		lvlup.WalletHandlerFunc(func(event lvlup.WalletEvent) {
+			events = append(events, event)
+		}),
+		lvlup.WithPaymentsCorrelation(),
+	)
+
+	assert.Nil(t, watcher.Poll())
+
+	for id := 2; id <= 251; id++ {
+		items = append(items, lvlup.ListPaymentsResultItem{Id: lvlup.PaymentItemID(id)})
+	}
+
+	balance = 3000
+	assert.Nil(t, watcher.Poll())
+
+	assert.Len(t, events, 1)
+	assert.Len(t, events[0].Payments, 250, "Payments of every page should be attached")
+	assert.Equal(t, lvlup.PaymentItemID(251), events[0].Payments[249].Id)
+}
+
+func Test_wallet_watcher_invalid_interval(t *testing.T) {
+	client := testutil.NewTestLvlClient("token", testutil.HttpError(http.StatusInternalServerError))
+	watcher := lvlup.NewWalletWatcher(client, lvlup.WalletHandlerFunc(func(lvlup.WalletEvent) {}), lvlup.WithWalletInterval(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.NotPanics(t, func() {
+		assert.Equal(t, context.Canceled, watcher.Run(ctx))
+	})
+}
+
+func Test_wallet_watcher_keeps_payments_of_failed_poll(t *testing.T) {
+	balance := 1000
+	items := []lvlup.ListPaymentsResultItem{{Id: 1}}
+	requests := 0
+
+	handler := testutil.Route(map[string]testutil.RoundTripFunc{
+		"/v4/wallet": func(r *http.Request) (*http.Response, error) {
+			return testutil.JSON(http.StatusOK, lvlup.WalletBalanceResult{BalancePlnInt: balance})(r)
+		},
+		"/v4/payments": func(r *http.Request) (*http.Response, error) {
+			requests++
+
+			if requests == 3 {
+				return testutil.HttpError(http.StatusInternalServerError)(r)
+			}
+
+			return testutil.PaymentsPager(items)(r)
+		},
+	})
+
+	var events []lvlup.WalletEvent
+	watcher := lvlup.NewWalletWatcher(
+		testutil.NewTestLvlClient("token", handler),
+		lvlup.WalletHandlerFunc(func(event lvlup.WalletEvent) {
+			events = append(events, event)
+		}),
+		lvlup.WithPaymentsCorrelation(),
+	)
+
+	assert.Nil(t, watcher.Poll())
+
+	for id := 2; id <= 151; id++ {
+		items = append(items, lvlup.ListPaymentsResultItem{Id: lvlup.PaymentItemID(id)})
+	}
+
+	balance = 3000
+	assert.NotNil(t, watcher.Poll(), "Error of the second page should be returned")
+	assert.Nil(t, watcher.Poll())
+
+	assert.Len(t, events, 1)
+	assert.Len(t, events[0].Payments, 150, "Payments of the failed poll should be attached")
+}